@@ -12,4 +12,12 @@ var (
 	DB_PASSWORD = os.Getenv("DB_PASSWORD")
 	DB_NAME     = os.Getenv("DB_NAME")
 	DB_PORT     = os.Getenv("DB_PORT")
+	// RESERVATION ENV VARS
+	RESERVATION_TTL_MINUTES = os.Getenv("RESERVATION_TTL_MINUTES")
+	// IDEMPOTENCY ENV VARS
+	IDEMPOTENCY_TTL_HOURS = os.Getenv("IDEMPOTENCY_TTL_HOURS")
+	// PRODUCT ENV VARS
+	PRODUCT_RETENTION_DAYS = os.Getenv("PRODUCT_RETENTION_DAYS")
+	// PURCHASE ENV VARS
+	MAX_PURCHASE_QUANTITY = os.Getenv("MAX_PURCHASE_QUANTITY")
 )
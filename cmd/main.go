@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	mw "ecomm/internal/delivery/middleware"
 	"ecomm/internal/delivery/restapi"
 	"ecomm/internal/repository"
@@ -9,7 +10,9 @@ import (
 	"os"
 	"os/signal"
 	"strconv"
+	"sync"
 	"syscall"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
@@ -41,15 +44,48 @@ func main() {
 	productRepo := repository.NewProductRepository(logger, db)
 	userRepo := repository.NewUserRepository(logger, db)
 	bankRepo := repository.NewBankRepository(logger, db)
+	paymentRepo := repository.NewPaymentRepository(logger, db)
+	reservationRepo := repository.NewReservationRepository(logger, db)
+	cartRepo := repository.NewCartRepository(logger, db)
+	orderRepo := repository.NewOrderRepository(logger, db)
+	idempotencyRepo := repository.NewIdempotencyRepository(logger, db)
+	categoryRepo := repository.NewCategoryRepository(logger, db)
+	tagRepo := repository.NewTagRepository(logger, db)
+	productImageRepo := repository.NewProductImageRepository(logger, db)
+	priceAlertRepo := repository.NewPriceAlertRepository(logger, db)
+	notificationRepo := repository.NewNotificationRepository(logger, db)
 	s3Repo := repository.NewS3Repository(logger)
 	salt, err := strconv.Atoi(os.Getenv("BCRYPT_SALT"))
 	if err != nil {
 		salt = 8
 	}
+	reservationTTL, err := strconv.Atoi(RESERVATION_TTL_MINUTES)
+	if err != nil {
+		reservationTTL = 15
+	}
+	idempotencyTTL, err := strconv.Atoi(IDEMPOTENCY_TTL_HOURS)
+	if err != nil {
+		idempotencyTTL = 24
+	}
+	productRetention, err := strconv.Atoi(PRODUCT_RETENTION_DAYS)
+	if err != nil {
+		productRetention = 30
+	}
+	maxPurchaseQuantity, err := strconv.Atoi(MAX_PURCHASE_QUANTITY)
+	if err != nil {
+		maxPurchaseQuantity = 100
+	}
 	// service registry
 	service := service.New(
-		service.Config{Salt: salt, JwtSecret: os.Getenv("JWT_SECRET")},
-		logger, productRepo, userRepo, s3Repo, bankRepo)
+		service.Config{
+			Salt:                salt,
+			JwtSecret:           os.Getenv("JWT_SECRET"),
+			ReservationTTL:      time.Duration(reservationTTL) * time.Minute,
+			IdempotencyTTL:      time.Duration(idempotencyTTL) * time.Hour,
+			ProductRetention:    time.Duration(productRetention) * 24 * time.Hour,
+			MaxPurchaseQuantity: maxPurchaseQuantity,
+		},
+		logger, productRepo, userRepo, s3Repo, bankRepo, paymentRepo, reservationRepo, cartRepo, orderRepo, idempotencyRepo, categoryRepo, tagRepo, productImageRepo, priceAlertRepo, notificationRepo)
 
 	// middleware init
 	md := mw.New(logger, service)
@@ -60,7 +96,10 @@ func main() {
 	// echo server
 	e := echo.New()
 	e.Use(middleware.Recover())
-	e.Use(middleware.CORS())
+	// browsers only hand the ETag to scripts when it is exposed, clients need it for If-Match
+	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
+		ExposeHeaders: []string{"ETag"},
+	}))
 	e.Use(middleware.RequestLoggerWithConfig(middleware.RequestLoggerConfig{
 		LogURI:    true,
 		LogStatus: true,
@@ -77,6 +116,79 @@ func main() {
 	// add restapi route
 	rest.MakeRoute(e)
 
+	// background jobs stop when the server shuts down
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	var jobs sync.WaitGroup
+
+	// release expired stock reservations in the background
+	jobs.Add(1)
+	go func() {
+		defer jobs.Done()
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-jobsCtx.Done():
+				return
+			case <-ticker.C:
+			}
+			released, _, err := service.ReleaseExpiredReservations(jobsCtx)
+			if err != nil {
+				logger.Error().Err(err).Msg("failed to release expired reservations")
+				continue
+			}
+			if released > 0 {
+				logger.Info().Msg(fmt.Sprintf("released %d expired reservations", released))
+			}
+		}
+	}()
+
+	// purge expired idempotency keys in the background
+	jobs.Add(1)
+	go func() {
+		defer jobs.Done()
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-jobsCtx.Done():
+				return
+			case <-ticker.C:
+			}
+			deleted, _, err := service.DeleteExpiredIdempotencyKeys(jobsCtx)
+			if err != nil {
+				logger.Error().Err(err).Msg("failed to delete expired idempotency keys")
+				continue
+			}
+			if deleted > 0 {
+				logger.Info().Msg(fmt.Sprintf("deleted %d expired idempotency keys", deleted))
+			}
+		}
+	}()
+
+	// purge products deleted longer than the retention period in the background
+	jobs.Add(1)
+	go func() {
+		defer jobs.Done()
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-jobsCtx.Done():
+				return
+			case <-ticker.C:
+			}
+			purged, _, err := service.PurgeDeletedProducts(jobsCtx)
+			if err != nil {
+				logger.Error().Err(err).Msg("failed to purge deleted products")
+				continue
+			}
+			if purged > 0 {
+				logger.Info().Msg(fmt.Sprintf("purged %d deleted products", purged))
+			}
+		}
+	}()
+
 	errs := make(chan error)
 	go func() {
 		logger.Log().Msg(fmt.Sprintf("start server on port %s", APP_PORT))
@@ -84,10 +196,18 @@ func main() {
 	}()
 
 	go func() {
-		c := make(chan os.Signal)
+		c := make(chan os.Signal, 1)
 		signal.Notify(c, syscall.SIGINT)
 		errs <- fmt.Errorf("%s", <-c)
 	}()
 
 	<-errs
+	stopJobs()
+	jobs.Wait()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := e.Shutdown(ctx); err != nil {
+		logger.Error().Err(err).Msg("failed to shut down the server")
+	}
 }
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_payments_user_created_at;
+
+ALTER TABLE PAYMENTS
+    DROP COLUMN STATUS,
+    DROP COLUMN PRODUCT_NAME,
+    DROP COLUMN PRODUCT_PRICE,
+    DROP COLUMN PRODUCT_IMAGE_URL;
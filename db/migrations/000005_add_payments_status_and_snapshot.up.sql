@@ -0,0 +1,14 @@
+ALTER TABLE PAYMENTS
+    ADD COLUMN STATUS VARCHAR(30) NOT NULL DEFAULT 'pending_verification',
+    ADD COLUMN PRODUCT_NAME VARCHAR(60) NOT NULL DEFAULT '',
+    ADD COLUMN PRODUCT_PRICE DECIMAL(20,0) NOT NULL DEFAULT 0,
+    ADD COLUMN PRODUCT_IMAGE_URL TEXT NOT NULL DEFAULT '';
+
+UPDATE PAYMENTS SET
+    PRODUCT_NAME = PRODUCTS.NAME,
+    PRODUCT_PRICE = PRODUCTS.PRICE,
+    PRODUCT_IMAGE_URL = PRODUCTS.IMAGE_URL
+FROM PRODUCTS
+WHERE PAYMENTS.PRODUCT_ID = PRODUCTS.ID;
+
+CREATE INDEX idx_payments_user_created_at ON PAYMENTS(USER_ID, CREATED_AT DESC);
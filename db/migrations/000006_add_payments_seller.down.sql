@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_payments_seller_created_at;
+
+ALTER TABLE PAYMENTS
+  DROP CONSTRAINT fk_payment_sellers;
+
+ALTER TABLE PAYMENTS DROP COLUMN SELLER_ID;
@@ -0,0 +1,11 @@
+ALTER TABLE PAYMENTS ADD COLUMN SELLER_ID INT;
+
+UPDATE PAYMENTS SET SELLER_ID = PRODUCTS.USER_ID
+FROM PRODUCTS
+WHERE PAYMENTS.PRODUCT_ID = PRODUCTS.ID;
+
+ALTER TABLE PAYMENTS
+    ALTER COLUMN SELLER_ID SET NOT NULL,
+    ADD CONSTRAINT fk_payment_sellers FOREIGN KEY(SELLER_ID) REFERENCES USERS(id);
+
+CREATE INDEX idx_payments_seller_created_at ON PAYMENTS(SELLER_ID, CREATED_AT DESC);
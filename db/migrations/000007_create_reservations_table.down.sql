@@ -0,0 +1,7 @@
+ALTER TABLE RESERVATIONS
+  DROP CONSTRAINT fk_reservation_users;
+
+ALTER TABLE RESERVATIONS
+  DROP CONSTRAINT fk_reservation_products;
+
+DROP TABLE RESERVATIONS;
@@ -0,0 +1,15 @@
+CREATE TABLE RESERVATIONS (
+    ID SERIAL PRIMARY KEY,
+    USER_ID INT NOT NULL,
+    PRODUCT_ID INT NOT NULL,
+    QUANTITY INT NOT NULL,
+    STATUS VARCHAR(20) NOT NULL,
+    EXPIRES_AT BIGINT NOT NULL,
+    CREATED_AT BIGINT NOT NULL,
+    UPDATED_AT BIGINT NOT NULL,
+    CONSTRAINT fk_reservation_users FOREIGN KEY(USER_ID) REFERENCES USERS(id),
+    CONSTRAINT fk_reservation_products FOREIGN KEY(PRODUCT_ID) REFERENCES PRODUCTS(id)
+);
+
+CREATE INDEX idx_reservations_active_product ON RESERVATIONS(PRODUCT_ID) WHERE STATUS = 'active';
+CREATE INDEX idx_reservations_active_expires_at ON RESERVATIONS(EXPIRES_AT) WHERE STATUS = 'active';
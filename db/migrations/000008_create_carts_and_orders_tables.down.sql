@@ -0,0 +1,23 @@
+ALTER TABLE PAYMENTS
+  DROP CONSTRAINT fk_payment_orders;
+
+ALTER TABLE PAYMENTS DROP COLUMN ORDER_ID;
+
+ALTER TABLE ORDERS
+  DROP CONSTRAINT fk_order_users;
+
+ALTER TABLE ORDERS
+  DROP CONSTRAINT fk_order_sellers;
+
+ALTER TABLE ORDERS
+  DROP CONSTRAINT fk_order_banks;
+
+DROP TABLE ORDERS;
+
+ALTER TABLE CART_ITEMS
+  DROP CONSTRAINT fk_cart_items_users;
+
+ALTER TABLE CART_ITEMS
+  DROP CONSTRAINT fk_cart_items_products;
+
+DROP TABLE CART_ITEMS;
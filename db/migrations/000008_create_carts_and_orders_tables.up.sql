@@ -0,0 +1,29 @@
+CREATE TABLE CART_ITEMS (
+    ID SERIAL PRIMARY KEY,
+    USER_ID INT NOT NULL,
+    PRODUCT_ID INT NOT NULL,
+    QUANTITY INT NOT NULL,
+    CREATED_AT BIGINT NOT NULL,
+    UPDATED_AT BIGINT NOT NULL,
+    CONSTRAINT uq_cart_items_user_product UNIQUE(USER_ID, PRODUCT_ID),
+    CONSTRAINT fk_cart_items_users FOREIGN KEY(USER_ID) REFERENCES USERS(id),
+    CONSTRAINT fk_cart_items_products FOREIGN KEY(PRODUCT_ID) REFERENCES PRODUCTS(id)
+);
+
+CREATE TABLE ORDERS (
+    ID SERIAL PRIMARY KEY,
+    USER_ID INT NOT NULL,
+    SELLER_ID INT NOT NULL,
+    BANK_ID INT NOT NULL,
+    PAYMENT_PROOF_IMAGE_URL TEXT NOT NULL,
+    TOTAL_PRICE DECIMAL(20,0) NOT NULL,
+    CREATED_AT BIGINT NOT NULL,
+    UPDATED_AT BIGINT NOT NULL,
+    CONSTRAINT fk_order_users FOREIGN KEY(USER_ID) REFERENCES USERS(id),
+    CONSTRAINT fk_order_sellers FOREIGN KEY(SELLER_ID) REFERENCES USERS(id),
+    CONSTRAINT fk_order_banks FOREIGN KEY(BANK_ID) REFERENCES BANKS(id)
+);
+
+ALTER TABLE PAYMENTS
+    ADD COLUMN ORDER_ID INT,
+    ADD CONSTRAINT fk_payment_orders FOREIGN KEY(ORDER_ID) REFERENCES ORDERS(id);
@@ -0,0 +1,4 @@
+ALTER TABLE IDEMPOTENCY_KEYS
+  DROP CONSTRAINT fk_idempotency_keys_users;
+
+DROP TABLE IDEMPOTENCY_KEYS;
@@ -0,0 +1,15 @@
+CREATE TABLE IDEMPOTENCY_KEYS (
+    ID SERIAL PRIMARY KEY,
+    USER_ID INT NOT NULL,
+    KEY VARCHAR(255) NOT NULL,
+    REQUEST_HASH VARCHAR(64) NOT NULL,
+    RESPONSE_CODE INT,
+    RESPONSE_BODY TEXT,
+    EXPIRES_AT BIGINT NOT NULL,
+    CREATED_AT BIGINT NOT NULL,
+    UPDATED_AT BIGINT NOT NULL,
+    CONSTRAINT uq_idempotency_keys_user_key UNIQUE(USER_ID, KEY),
+    CONSTRAINT fk_idempotency_keys_users FOREIGN KEY(USER_ID) REFERENCES USERS(id)
+);
+
+CREATE INDEX idx_idempotency_keys_expires_at ON IDEMPOTENCY_KEYS(EXPIRES_AT);
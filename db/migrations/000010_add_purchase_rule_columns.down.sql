@@ -0,0 +1,3 @@
+ALTER TABLE USERS DROP COLUMN IS_SUSPENDED;
+
+ALTER TABLE PRODUCTS DROP COLUMN PURCHASE_LIMIT_PER_BUYER;
@@ -0,0 +1,3 @@
+ALTER TABLE PRODUCTS ADD COLUMN PURCHASE_LIMIT_PER_BUYER INT NOT NULL DEFAULT 0;
+
+ALTER TABLE USERS ADD COLUMN IS_SUSPENDED BOOLEAN NOT NULL DEFAULT FALSE;
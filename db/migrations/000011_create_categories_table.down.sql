@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS idx_products_category_id;
+
+ALTER TABLE PRODUCTS
+  DROP CONSTRAINT fk_products_category;
+
+ALTER TABLE PRODUCTS DROP COLUMN CATEGORY_ID;
+
+ALTER TABLE CATEGORIES
+  DROP CONSTRAINT fk_categories_parent;
+
+DROP TABLE CATEGORIES;
+
+ALTER TABLE USERS DROP COLUMN IS_ADMIN;
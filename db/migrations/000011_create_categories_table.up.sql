@@ -0,0 +1,18 @@
+ALTER TABLE USERS ADD COLUMN IS_ADMIN BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE CATEGORIES (
+    ID SERIAL PRIMARY KEY,
+    NAME VARCHAR(50) NOT NULL,
+    PARENT_ID INT,
+    CREATED_AT BIGINT NOT NULL,
+    UPDATED_AT BIGINT NOT NULL,
+    CONSTRAINT fk_categories_parent FOREIGN KEY(PARENT_ID) REFERENCES CATEGORIES(id)
+);
+
+CREATE INDEX idx_categories_parent_id ON CATEGORIES(PARENT_ID);
+
+ALTER TABLE PRODUCTS
+    ADD COLUMN CATEGORY_ID INT,
+    ADD CONSTRAINT fk_products_category FOREIGN KEY(CATEGORY_ID) REFERENCES CATEGORIES(id);
+
+CREATE INDEX idx_products_category_id ON PRODUCTS(CATEGORY_ID);
@@ -0,0 +1,16 @@
+ALTER TABLE PRODUCTS ADD COLUMN TAGS TEXT NOT NULL DEFAULT '';
+
+UPDATE PRODUCTS SET TAGS = t.TAGS
+FROM (
+    SELECT PRODUCT_ID, STRING_AGG(TAG, ',' ORDER BY TAG) AS TAGS
+    FROM PRODUCT_TAGS
+    GROUP BY PRODUCT_ID
+) AS t
+WHERE PRODUCTS.ID = t.PRODUCT_ID;
+
+ALTER TABLE PRODUCTS ALTER COLUMN TAGS DROP DEFAULT;
+
+ALTER TABLE PRODUCT_TAGS
+  DROP CONSTRAINT fk_product_tags_products;
+
+DROP TABLE PRODUCT_TAGS;
@@ -0,0 +1,15 @@
+CREATE TABLE PRODUCT_TAGS (
+    PRODUCT_ID INT NOT NULL,
+    TAG VARCHAR(60) NOT NULL,
+    CONSTRAINT pk_product_tags PRIMARY KEY(PRODUCT_ID, TAG),
+    CONSTRAINT fk_product_tags_products FOREIGN KEY(PRODUCT_ID) REFERENCES PRODUCTS(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_product_tags_tag ON PRODUCT_TAGS(TAG);
+
+INSERT INTO PRODUCT_TAGS (PRODUCT_ID, TAG)
+SELECT DISTINCT PRODUCTS.ID, LOWER(TRIM(t.TAG))
+FROM PRODUCTS, UNNEST(STRING_TO_ARRAY(PRODUCTS.TAGS, ',')) AS t(TAG)
+WHERE TRIM(t.TAG) <> '';
+
+ALTER TABLE PRODUCTS DROP COLUMN TAGS;
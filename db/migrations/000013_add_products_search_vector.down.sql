@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_products_search_vector;
+
+DROP TRIGGER IF EXISTS trg_product_tags_search_vector ON PRODUCT_TAGS;
+DROP FUNCTION IF EXISTS product_tags_search_vector_trigger();
+
+DROP TRIGGER IF EXISTS trg_products_search_vector ON PRODUCTS;
+DROP FUNCTION IF EXISTS products_search_vector_trigger();
+
+DROP FUNCTION IF EXISTS product_search_vector(TEXT, INT);
+
+ALTER TABLE PRODUCTS DROP COLUMN SEARCH_VECTOR;
@@ -0,0 +1,36 @@
+ALTER TABLE PRODUCTS ADD COLUMN SEARCH_VECTOR TSVECTOR;
+
+-- the product id is referenced positionally ($2), a parameter named like the PRODUCT_ID column would resolve to the column
+CREATE OR REPLACE FUNCTION product_search_vector(product_name TEXT, product_id INT) RETURNS TSVECTOR AS $$
+    SELECT
+        setweight(to_tsvector('english', COALESCE(product_name, '')), 'A') ||
+        setweight(to_tsvector('english', COALESCE((SELECT STRING_AGG(TAG, ' ') FROM PRODUCT_TAGS WHERE PRODUCT_TAGS.PRODUCT_ID = $2), '')), 'B')
+$$ LANGUAGE SQL STABLE;
+
+CREATE OR REPLACE FUNCTION products_search_vector_trigger() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.SEARCH_VECTOR := product_search_vector(NEW.NAME, NEW.ID);
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_products_search_vector
+    BEFORE INSERT OR UPDATE OF NAME ON PRODUCTS
+    FOR EACH ROW EXECUTE FUNCTION products_search_vector_trigger();
+
+CREATE OR REPLACE FUNCTION product_tags_search_vector_trigger() RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE PRODUCTS
+    SET SEARCH_VECTOR = product_search_vector(NAME, ID)
+    WHERE ID = COALESCE(NEW.PRODUCT_ID, OLD.PRODUCT_ID);
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_product_tags_search_vector
+    AFTER INSERT OR UPDATE OR DELETE ON PRODUCT_TAGS
+    FOR EACH ROW EXECUTE FUNCTION product_tags_search_vector_trigger();
+
+UPDATE PRODUCTS SET SEARCH_VECTOR = product_search_vector(NAME, ID);
+
+CREATE INDEX idx_products_search_vector ON PRODUCTS USING GIN(SEARCH_VECTOR);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_product_tags_tag_trgm;
+DROP INDEX IF EXISTS idx_products_name_trgm;
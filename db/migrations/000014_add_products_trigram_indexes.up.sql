@@ -0,0 +1,4 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX idx_products_name_trgm ON PRODUCTS USING GIN(LOWER(NAME) gin_trgm_ops);
+CREATE INDEX idx_product_tags_tag_trgm ON PRODUCT_TAGS USING GIN(TAG gin_trgm_ops);
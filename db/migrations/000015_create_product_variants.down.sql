@@ -0,0 +1,7 @@
+ALTER TABLE PAYMENTS
+    DROP CONSTRAINT IF EXISTS fk_payment_variants,
+    DROP COLUMN VARIANT_SKU,
+    DROP COLUMN VARIANT_ID;
+
+DROP TABLE IF EXISTS PRODUCT_VARIANTS;
+DROP TABLE IF EXISTS PRODUCT_OPTIONS;
@@ -0,0 +1,29 @@
+CREATE TABLE PRODUCT_OPTIONS (
+    PRODUCT_ID INT NOT NULL,
+    POSITION INT NOT NULL,
+    NAME VARCHAR(30) NOT NULL,
+    OPTION_VALUES TEXT[] NOT NULL,
+    CONSTRAINT pk_product_options PRIMARY KEY(PRODUCT_ID, POSITION),
+    CONSTRAINT uq_product_options_name UNIQUE(PRODUCT_ID, NAME),
+    CONSTRAINT fk_product_options_products FOREIGN KEY(PRODUCT_ID) REFERENCES PRODUCTS(id) ON DELETE CASCADE
+);
+
+CREATE TABLE PRODUCT_VARIANTS (
+    ID SERIAL PRIMARY KEY,
+    PRODUCT_ID INT NOT NULL,
+    SKU VARCHAR(64) NOT NULL,
+    OPTION_VALUES TEXT[] NOT NULL,
+    PRICE INT,
+    STOCK INT NOT NULL DEFAULT 0,
+    IMAGE_URL TEXT,
+    CREATED_AT BIGINT NOT NULL,
+    UPDATED_AT BIGINT NOT NULL,
+    CONSTRAINT uq_product_variants_sku UNIQUE(PRODUCT_ID, SKU),
+    CONSTRAINT uq_product_variants_options UNIQUE(PRODUCT_ID, OPTION_VALUES),
+    CONSTRAINT fk_product_variants_products FOREIGN KEY(PRODUCT_ID) REFERENCES PRODUCTS(id) ON DELETE CASCADE
+);
+
+ALTER TABLE PAYMENTS
+    ADD COLUMN VARIANT_ID INT,
+    ADD COLUMN VARIANT_SKU VARCHAR(64),
+    ADD CONSTRAINT fk_payment_variants FOREIGN KEY(VARIANT_ID) REFERENCES PRODUCT_VARIANTS(id) ON DELETE SET NULL;
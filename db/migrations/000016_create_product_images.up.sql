@@ -0,0 +1,16 @@
+CREATE TABLE PRODUCT_IMAGES (
+    ID SERIAL PRIMARY KEY,
+    PRODUCT_ID INT NOT NULL,
+    URL TEXT NOT NULL,
+    ALT_TEXT VARCHAR(125) NOT NULL DEFAULT '',
+    POSITION INT NOT NULL,
+    CREATED_AT BIGINT NOT NULL,
+    CONSTRAINT fk_product_images_products FOREIGN KEY(PRODUCT_ID) REFERENCES PRODUCTS(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_product_images_product_position ON PRODUCT_IMAGES(PRODUCT_ID, POSITION);
+
+INSERT INTO PRODUCT_IMAGES (PRODUCT_ID, URL, POSITION, CREATED_AT)
+SELECT ID, IMAGE_URL, 0, CREATED_AT
+FROM PRODUCTS
+WHERE IMAGE_URL <> '';
@@ -0,0 +1,33 @@
+ALTER TABLE PRODUCTS ADD COLUMN DESCRIPTION TEXT NOT NULL DEFAULT '';
+
+CREATE OR REPLACE FUNCTION product_search_vector(product_name TEXT, product_description TEXT, product_id INT) RETURNS TSVECTOR AS $$
+    SELECT
+        setweight(to_tsvector('english', COALESCE(product_name, '')), 'A') ||
+        setweight(to_tsvector('english', COALESCE((SELECT STRING_AGG(TAG, ' ') FROM PRODUCT_TAGS WHERE PRODUCT_TAGS.PRODUCT_ID = $3), '')), 'B') ||
+        setweight(to_tsvector('english', COALESCE(product_description, '')), 'C')
+$$ LANGUAGE SQL STABLE;
+
+CREATE OR REPLACE FUNCTION products_search_vector_trigger() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.SEARCH_VECTOR := product_search_vector(NEW.NAME, NEW.DESCRIPTION, NEW.ID);
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION product_tags_search_vector_trigger() RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE PRODUCTS
+    SET SEARCH_VECTOR = product_search_vector(NAME, DESCRIPTION, ID)
+    WHERE ID = COALESCE(NEW.PRODUCT_ID, OLD.PRODUCT_ID);
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_products_search_vector ON PRODUCTS;
+CREATE TRIGGER trg_products_search_vector
+    BEFORE INSERT OR UPDATE OF NAME, DESCRIPTION ON PRODUCTS
+    FOR EACH ROW EXECUTE FUNCTION products_search_vector_trigger();
+
+DROP FUNCTION IF EXISTS product_search_vector(TEXT, INT);
+
+UPDATE PRODUCTS SET SEARCH_VECTOR = product_search_vector(NAME, DESCRIPTION, ID);
@@ -0,0 +1,3 @@
+ALTER TABLE PRODUCTS ADD COLUMN DELETED_AT BIGINT;
+
+CREATE INDEX idx_products_deleted_at ON PRODUCTS(DELETED_AT) WHERE DELETED_AT IS NOT NULL;
@@ -0,0 +1,6 @@
+ALTER TABLE PAYMENTS DROP COLUMN IF EXISTS PRODUCT_VERSION;
+
+DROP FUNCTION IF EXISTS product_snapshot(INT);
+DROP TABLE IF EXISTS PRODUCT_VERSIONS;
+
+ALTER TABLE PRODUCTS DROP COLUMN IF EXISTS VERSION;
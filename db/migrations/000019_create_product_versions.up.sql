@@ -0,0 +1,55 @@
+ALTER TABLE PRODUCTS ADD COLUMN VERSION INT NOT NULL DEFAULT 0;
+
+CREATE TABLE PRODUCT_VERSIONS (
+    ID SERIAL PRIMARY KEY,
+    PRODUCT_ID INT NOT NULL,
+    VERSION INT NOT NULL,
+    ACTION VARCHAR(20) NOT NULL,
+    SNAPSHOT JSONB NOT NULL,
+    USER_ID INT NOT NULL,
+    CREATED_AT BIGINT NOT NULL,
+    CONSTRAINT uq_product_versions UNIQUE(PRODUCT_ID, VERSION),
+    CONSTRAINT fk_product_versions_products FOREIGN KEY(PRODUCT_ID) REFERENCES PRODUCTS(id) ON DELETE CASCADE,
+    CONSTRAINT fk_product_versions_users FOREIGN KEY(USER_ID) REFERENCES USERS(id)
+);
+
+-- product_snapshot returns the listing of a product as stored in PRODUCT_VERSIONS
+CREATE OR REPLACE FUNCTION product_snapshot(product_id INT) RETURNS JSONB AS $$
+    SELECT jsonb_build_object(
+        'name', p.NAME,
+        'description', p.DESCRIPTION,
+        'price', p.PRICE,
+        'imageUrl', p.IMAGE_URL,
+        'stock', p.STOCK,
+        'condition', p.CONDITION,
+        'categoryId', p.CATEGORY_ID::TEXT,
+        'isPurchasable', p.IS_PURCHASABLE,
+        'purchaseLimitPerBuyer', p.PURCHASE_LIMIT_PER_BUYER,
+        'deletedAt', p.DELETED_AT,
+        'tags', COALESCE((SELECT jsonb_agg(t.TAG ORDER BY t.TAG) FROM PRODUCT_TAGS t WHERE t.PRODUCT_ID = $1), '[]'::JSONB),
+        'options', COALESCE((
+            SELECT jsonb_agg(jsonb_build_object('name', o.NAME, 'values', o.OPTION_VALUES) ORDER BY o.POSITION)
+            FROM PRODUCT_OPTIONS o WHERE o.PRODUCT_ID = $1
+        ), '[]'::JSONB),
+        'variants', COALESCE((
+            SELECT jsonb_agg(jsonb_build_object(
+                'variantId', v.ID::TEXT,
+                'sku', v.SKU,
+                'options', v.OPTION_VALUES,
+                'price', v.PRICE,
+                'stock', v.STOCK,
+                'imageUrl', v.IMAGE_URL
+            ) ORDER BY v.SKU)
+            FROM PRODUCT_VARIANTS v WHERE v.PRODUCT_ID = $1
+        ), '[]'::JSONB)
+    )
+    FROM PRODUCTS p
+    WHERE p.ID = $1
+$$ LANGUAGE SQL STABLE;
+
+-- existing products start their history at version 1
+UPDATE PRODUCTS SET VERSION = 1;
+INSERT INTO PRODUCT_VERSIONS (PRODUCT_ID, VERSION, ACTION, SNAPSHOT, USER_ID, CREATED_AT)
+SELECT ID, 1, 'create', product_snapshot(ID), USER_ID, UPDATED_AT FROM PRODUCTS;
+
+ALTER TABLE PAYMENTS ADD COLUMN PRODUCT_VERSION INT;
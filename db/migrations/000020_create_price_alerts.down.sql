@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS NOTIFICATIONS;
+DROP TABLE IF EXISTS PRICE_ALERTS;
+DROP TABLE IF EXISTS PRODUCT_PRICES;
@@ -0,0 +1,47 @@
+CREATE TABLE PRODUCT_PRICES (
+    ID SERIAL PRIMARY KEY,
+    PRODUCT_ID INT NOT NULL,
+    PRICE INT NOT NULL,
+    CREATED_AT BIGINT NOT NULL,
+    CONSTRAINT fk_product_prices_products FOREIGN KEY(PRODUCT_ID) REFERENCES PRODUCTS(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_product_prices_product_id ON PRODUCT_PRICES(PRODUCT_ID, CREATED_AT);
+
+-- the price history starts from the price changes already recorded in the product history
+INSERT INTO PRODUCT_PRICES (PRODUCT_ID, PRICE, CREATED_AT)
+SELECT PRODUCT_ID, PRICE, CREATED_AT
+FROM (
+    SELECT
+        PRODUCT_ID,
+        (SNAPSHOT->>'price')::INT as PRICE,
+        LAG((SNAPSHOT->>'price')::INT) OVER (PARTITION BY PRODUCT_ID ORDER BY VERSION) as PREVIOUS_PRICE,
+        CREATED_AT
+    FROM PRODUCT_VERSIONS
+) as v
+WHERE PREVIOUS_PRICE IS NULL OR PREVIOUS_PRICE <> PRICE;
+
+CREATE TABLE PRICE_ALERTS (
+    ID SERIAL PRIMARY KEY,
+    PRODUCT_ID INT NOT NULL,
+    USER_ID INT NOT NULL,
+    TARGET_PRICE INT NOT NULL,
+    CREATED_AT BIGINT NOT NULL,
+    CONSTRAINT uq_price_alerts UNIQUE(PRODUCT_ID, USER_ID),
+    CONSTRAINT fk_price_alerts_products FOREIGN KEY(PRODUCT_ID) REFERENCES PRODUCTS(id) ON DELETE CASCADE,
+    CONSTRAINT fk_price_alerts_users FOREIGN KEY(USER_ID) REFERENCES USERS(id) ON DELETE CASCADE
+);
+
+CREATE TABLE NOTIFICATIONS (
+    ID SERIAL PRIMARY KEY,
+    USER_ID INT NOT NULL,
+    TYPE VARCHAR(30) NOT NULL,
+    PRODUCT_ID INT,
+    MESSAGE TEXT NOT NULL,
+    READ_AT BIGINT,
+    CREATED_AT BIGINT NOT NULL,
+    CONSTRAINT fk_notifications_users FOREIGN KEY(USER_ID) REFERENCES USERS(id) ON DELETE CASCADE,
+    CONSTRAINT fk_notifications_products FOREIGN KEY(PRODUCT_ID) REFERENCES PRODUCTS(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_notifications_user_id ON NOTIFICATIONS(USER_ID, CREATED_AT);
@@ -0,0 +1 @@
+ALTER TABLE BANKS DROP COLUMN IF EXISTS VERSION;
@@ -0,0 +1 @@
+ALTER TABLE BANKS ADD COLUMN VERSION INT NOT NULL DEFAULT 1;
@@ -0,0 +1,3 @@
+ALTER TABLE IDEMPOTENCY_KEYS
+    DROP COLUMN IF EXISTS RESPONSE_CONTENT_DISPOSITION,
+    DROP COLUMN IF EXISTS RESPONSE_CONTENT_TYPE;
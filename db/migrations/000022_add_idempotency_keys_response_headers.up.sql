@@ -0,0 +1,3 @@
+ALTER TABLE IDEMPOTENCY_KEYS
+    ADD COLUMN RESPONSE_CONTENT_TYPE VARCHAR(255),
+    ADD COLUMN RESPONSE_CONTENT_DISPOSITION VARCHAR(255);
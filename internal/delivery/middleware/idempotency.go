@@ -0,0 +1,143 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"ecomm/internal/helper/common"
+	httpHelper "ecomm/internal/helper/http"
+	"ecomm/internal/model/request"
+	"ecomm/internal/model/response"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// bodyRecorder keeps a copy of the response body written by the handler
+type bodyRecorder struct {
+	http.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w *bodyRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// Idempotency replays the recorded response when a request is retried with the same Idempotency-Key header.
+// It must run after Authentication since keys are scoped per user
+func (m *middleware) Idempotency(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		key := c.Request().Header.Get(idempotencyKeyHeader)
+		if key == "" {
+			return next(c)
+		}
+		usr := c.Get(common.EncodedUserJwtCtxKey.ToString()).(*response.User)
+
+		requestHash, err := hashRequest(c)
+		if err != nil {
+			return httpHelper.ResponseJSONHTTP(c, http.StatusBadRequest, "", nil, nil, err)
+		}
+		req := request.IdempotencyKey{
+			UserID:      usr.ID,
+			Key:         key,
+			RequestHash: requestHash,
+		}
+
+		existing, code, err := m.service.AcquireIdempotencyKey(c.Request().Context(), req)
+		if err != nil {
+			m.logger.Debug().Stack().Err(err).Send()
+			return httpHelper.ResponseJSONHTTP(c, code, "", nil, nil, err)
+		}
+		if existing != nil {
+			c.Response().Header().Set("Idempotent-Replayed", "true")
+			if existing.ResponseContentType == "" {
+				return c.JSONBlob(existing.ResponseCode, []byte(existing.ResponseBody))
+			}
+			if existing.ResponseContentDisposition != "" {
+				c.Response().Header().Set(echo.HeaderContentDisposition, existing.ResponseContentDisposition)
+			}
+			return c.Blob(existing.ResponseCode, existing.ResponseContentType, []byte(existing.ResponseBody))
+		}
+
+		recorder := &bodyRecorder{ResponseWriter: c.Response().Writer, body: &bytes.Buffer{}}
+		c.Response().Writer = recorder
+
+		err = next(c)
+
+		// server errors are not recorded so the client can retry them with the same key
+		if err != nil || c.Response().Status >= http.StatusInternalServerError {
+			if _, releaseErr := m.service.ReleaseIdempotencyKey(c.Request().Context(), usr.ID, key); releaseErr != nil {
+				m.logger.Error().Err(releaseErr).Msg("failed to release idempotency key")
+			}
+			return err
+		}
+
+		req.ResponseCode = c.Response().Status
+		req.ResponseBody = recorder.body.String()
+		req.ResponseContentType = c.Response().Header().Get(echo.HeaderContentType)
+		req.ResponseContentDisposition = c.Response().Header().Get(echo.HeaderContentDisposition)
+		if _, err := m.service.SaveIdempotencyResponse(c.Request().Context(), req); err != nil {
+			m.logger.Error().Err(err).Msg("failed to save idempotent response")
+		}
+
+		return nil
+	}
+}
+
+// hashRequest identifies the request a key is used for by its method, URI and body. Multipart bodies are
+// hashed from their parsed fields and files, the boundary is picked anew by the client on every retry
+func hashRequest(c echo.Context) (string, error) {
+	hash := sha256.New()
+	hash.Write([]byte(c.Request().Method + " " + c.Request().URL.RequestURI() + "\n"))
+
+	if !strings.HasPrefix(c.Request().Header.Get(echo.HeaderContentType), echo.MIMEMultipartForm) {
+		body, err := io.ReadAll(c.Request().Body)
+		if err != nil {
+			return "", err
+		}
+		c.Request().Body = io.NopCloser(bytes.NewReader(body))
+		hash.Write(body)
+		return hex.EncodeToString(hash.Sum(nil)), nil
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		return "", err
+	}
+	for _, name := range sortedKeys(form.Value) {
+		for _, value := range form.Value[name] {
+			fmt.Fprintf(hash, "%q=%q\n", name, value)
+		}
+	}
+	for _, name := range sortedKeys(form.File) {
+		for _, file := range form.File[name] {
+			fmt.Fprintf(hash, "%q:%q:%d\n", name, file.Filename, file.Size)
+			src, err := file.Open()
+			if err != nil {
+				return "", err
+			}
+			_, err = io.Copy(hash, src)
+			src.Close()
+			if err != nil {
+				return "", err
+			}
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -24,6 +24,10 @@ type Middleware interface {
 	Authentication(isThrowError bool) func(next echo.HandlerFunc) echo.HandlerFunc
 	IsProductOwner(next echo.HandlerFunc) echo.HandlerFunc
 	IsBankOwner(next echo.HandlerFunc) echo.HandlerFunc
+	IsPaymentSeller(next echo.HandlerFunc) echo.HandlerFunc
+	IsPaymentBuyer(next echo.HandlerFunc) echo.HandlerFunc
+	Idempotency(next echo.HandlerFunc) echo.HandlerFunc
+	IsAdmin(next echo.HandlerFunc) echo.HandlerFunc
 }
 
 func New(logger zerolog.Logger, service service.Service) Middleware {
@@ -104,3 +108,55 @@ func (m *middleware) IsBankOwner(next echo.HandlerFunc) echo.HandlerFunc {
 		return next(c)
 	}
 }
+
+func (m *middleware) IsPaymentSeller(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		usr := c.Get(common.EncodedUserJwtCtxKey.ToString()).(*response.User)
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			return httpHelper.ResponseJSONHTTP(c, http.StatusBadRequest, "", nil, nil, err)
+		}
+
+		payment, code, err := m.service.GetPaymentByID(c.Request().Context(), int64(id))
+		if err != nil {
+			m.logger.Debug().Stack().Err(err).Send()
+			return httpHelper.ResponseJSONHTTP(c, code, "", nil, nil, err)
+		}
+
+		if payment.SellerID != usr.ID {
+			return httpHelper.ResponseJSONHTTP(c, http.StatusForbidden, "", nil, nil, errorer.ErrForbidden)
+		}
+		return next(c)
+	}
+}
+
+func (m *middleware) IsPaymentBuyer(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		usr := c.Get(common.EncodedUserJwtCtxKey.ToString()).(*response.User)
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			return httpHelper.ResponseJSONHTTP(c, http.StatusBadRequest, "", nil, nil, err)
+		}
+
+		payment, code, err := m.service.GetPaymentByID(c.Request().Context(), int64(id))
+		if err != nil {
+			m.logger.Debug().Stack().Err(err).Send()
+			return httpHelper.ResponseJSONHTTP(c, code, "", nil, nil, err)
+		}
+
+		if payment.UserID != usr.ID {
+			return httpHelper.ResponseJSONHTTP(c, http.StatusForbidden, "", nil, nil, errorer.ErrForbidden)
+		}
+		return next(c)
+	}
+}
+
+func (m *middleware) IsAdmin(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		usr := c.Get(common.EncodedUserJwtCtxKey.ToString()).(*response.User)
+		if !usr.IsAdmin {
+			return httpHelper.ResponseJSONHTTP(c, http.StatusForbidden, "", nil, nil, errorer.ErrForbidden)
+		}
+		return next(c)
+	}
+}
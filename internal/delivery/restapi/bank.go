@@ -31,7 +31,12 @@ func (r *Restapi) DeleteBankByID(c echo.Context) error {
 		return httpHelper.ResponseJSONHTTP(c, http.StatusBadRequest, "", nil, nil, err)
 	}
 
-	code, err := r.service.DeleteBankByID(c.Request().Context(), int64(id))
+	version, code, err := httpHelper.GetIfMatchVersion(c.Request())
+	if err != nil {
+		return httpHelper.ResponseJSONHTTP(c, code, "", nil, nil, err)
+	}
+
+	code, err = r.service.DeleteBankByID(c.Request().Context(), int64(id), version)
 	r.debugError(err)
 	return httpHelper.ResponseJSONHTTP(c, code, "", nil, nil, err)
 }
@@ -47,8 +52,13 @@ func (r *Restapi) PatchBankByID(c echo.Context) error {
 		return httpHelper.ResponseJSONHTTP(c, http.StatusBadRequest, "", nil, nil, err)
 	}
 	req.ID = int64(id)
+	version, code, err := httpHelper.GetIfMatchVersion(c.Request())
+	if err != nil {
+		return httpHelper.ResponseJSONHTTP(c, code, "", nil, nil, err)
+	}
+	req.Version = version
 
-	code, err := r.service.UpdateBankByID(c.Request().Context(), req)
+	code, err = r.service.UpdateBankByID(c.Request().Context(), req)
 	r.debugError(err)
 	return httpHelper.ResponseJSONHTTP(c, code, "", nil, nil, err)
 }
@@ -64,3 +74,17 @@ func (r *Restapi) GetBanks(c echo.Context) error {
 	return httpHelper.ResponseJSONHTTP(c, code, "",
 		banks, nil, err)
 }
+
+func (r *Restapi) GetBankByID(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return httpHelper.ResponseJSONHTTP(c, http.StatusBadRequest, "", nil, nil, err)
+	}
+
+	bank, code, err := r.service.GetBankByID(c.Request().Context(), int64(id))
+	r.debugError(err)
+	if bank != nil {
+		c.Response().Header().Set("ETag", httpHelper.ETag(bank.Version))
+	}
+	return httpHelper.ResponseJSONHTTP(c, code, "", bank, nil, err)
+}
@@ -0,0 +1,77 @@
+package restapi
+
+import (
+	"ecomm/internal/helper/common"
+	httpHelper "ecomm/internal/helper/http"
+	"ecomm/internal/model/request"
+	"ecomm/internal/model/response"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (r *Restapi) GetCart(c echo.Context) error {
+	usr := c.Get(common.EncodedUserJwtCtxKey.ToString()).(*response.User)
+
+	cart, code, err := r.service.GetCart(c.Request().Context(), usr.ID)
+	r.debugError(err)
+	return httpHelper.ResponseJSONHTTP(c, code, "", cart, nil, err)
+}
+
+func (r *Restapi) AddCartItem(c echo.Context) error {
+	req := request.AddCartItem{}
+	if err := c.Bind(&req); err != nil {
+		return httpHelper.ResponseJSONHTTP(c, http.StatusBadRequest, "", nil, nil, err)
+	}
+	req.UserID = c.Get(common.EncodedUserJwtCtxKey.ToString()).(*response.User).ID
+
+	code, err := r.service.AddCartItem(c.Request().Context(), req)
+	r.debugError(err)
+	return httpHelper.ResponseJSONHTTP(c, code, "", nil, nil, err)
+}
+
+func (r *Restapi) PatchCartItem(c echo.Context) error {
+	productId, err := strconv.Atoi(c.Param("productId"))
+	if err != nil {
+		return httpHelper.ResponseJSONHTTP(c, http.StatusBadRequest, "", nil, nil, err)
+	}
+
+	req := request.UpdateCartItem{}
+	if err := c.Bind(&req); err != nil {
+		return httpHelper.ResponseJSONHTTP(c, http.StatusBadRequest, "", nil, nil, err)
+	}
+	req.ProductId = int64(productId)
+	req.UserID = c.Get(common.EncodedUserJwtCtxKey.ToString()).(*response.User).ID
+
+	code, err := r.service.UpdateCartItem(c.Request().Context(), req)
+	r.debugError(err)
+	return httpHelper.ResponseJSONHTTP(c, code, "", nil, nil, err)
+}
+
+func (r *Restapi) DeleteCartItem(c echo.Context) error {
+	productId, err := strconv.Atoi(c.Param("productId"))
+	if err != nil {
+		return httpHelper.ResponseJSONHTTP(c, http.StatusBadRequest, "", nil, nil, err)
+	}
+	usr := c.Get(common.EncodedUserJwtCtxKey.ToString()).(*response.User)
+
+	code, err := r.service.DeleteCartItem(c.Request().Context(), usr.ID, int64(productId))
+	r.debugError(err)
+	return httpHelper.ResponseJSONHTTP(c, code, "", nil, nil, err)
+}
+
+func (r *Restapi) Checkout(c echo.Context) error {
+	req := request.Checkout{}
+	if err := c.Bind(&req); err != nil {
+		return httpHelper.ResponseJSONHTTP(c, http.StatusBadRequest, "", nil, nil, err)
+	}
+	req.UserID = c.Get(common.EncodedUserJwtCtxKey.ToString()).(*response.User).ID
+
+	orders, code, err := r.service.Checkout(c.Request().Context(), req)
+	r.debugError(err)
+	return httpHelper.ResponseJSONHTTP(c, code, "",
+		map[string]interface{}{
+			"orders": orders,
+		}, nil, err)
+}
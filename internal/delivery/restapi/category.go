@@ -0,0 +1,58 @@
+package restapi
+
+import (
+	httpHelper "ecomm/internal/helper/http"
+	"ecomm/internal/model/request"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (r *Restapi) GetCategories(c echo.Context) error {
+	categories, code, err := r.service.GetCategories(c.Request().Context())
+	r.debugError(err)
+	return httpHelper.ResponseJSONHTTP(c, code, "",
+		map[string]interface{}{
+			"categories": categories,
+		}, nil, err)
+}
+
+func (r *Restapi) CreateCategory(c echo.Context) error {
+	req := request.Category{}
+	if err := c.Bind(&req); err != nil {
+		return httpHelper.ResponseJSONHTTP(c, http.StatusBadRequest, "", nil, nil, err)
+	}
+
+	category, code, err := r.service.CreateCategory(c.Request().Context(), req)
+	r.debugError(err)
+	return httpHelper.ResponseJSONHTTP(c, code, "", category, nil, err)
+}
+
+func (r *Restapi) PatchCategoryByID(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return httpHelper.ResponseJSONHTTP(c, http.StatusBadRequest, "", nil, nil, err)
+	}
+
+	req := request.UpdateCategory{}
+	if err := c.Bind(&req); err != nil {
+		return httpHelper.ResponseJSONHTTP(c, http.StatusBadRequest, "", nil, nil, err)
+	}
+	req.ID = int64(id)
+
+	code, err := r.service.UpdateCategoryByID(c.Request().Context(), req)
+	r.debugError(err)
+	return httpHelper.ResponseJSONHTTP(c, code, "", nil, nil, err)
+}
+
+func (r *Restapi) DeleteCategoryByID(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return httpHelper.ResponseJSONHTTP(c, http.StatusBadRequest, "", nil, nil, err)
+	}
+
+	code, err := r.service.DeleteCategoryByID(c.Request().Context(), int64(id))
+	r.debugError(err)
+	return httpHelper.ResponseJSONHTTP(c, code, "", nil, nil, err)
+}
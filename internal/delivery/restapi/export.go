@@ -0,0 +1,79 @@
+package restapi
+
+import (
+	"context"
+	"ecomm/internal/helper/common"
+	httpHelper "ecomm/internal/helper/http"
+	"ecomm/internal/model/request"
+	"ecomm/internal/model/response"
+	"ecomm/internal/service"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+var exportContentTypes = map[string]string{
+	service.ExportFormatCSV:    "text/csv",
+	service.ExportFormatNDJSON: "application/x-ndjson",
+}
+
+// exportWriter sends the response headers on the first write, until then a failed export can still
+// answer with a JSON error
+type exportWriter struct {
+	c        echo.Context
+	filename string
+	format   string
+	started  bool
+}
+
+func (w *exportWriter) start() {
+	w.started = true
+	res := w.c.Response()
+	res.Header().Set(echo.HeaderContentType, exportContentTypes[w.format])
+	res.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s.%s"`, w.filename, w.format))
+	res.WriteHeader(http.StatusOK)
+}
+
+func (w *exportWriter) Write(p []byte) (int, error) {
+	if !w.started {
+		w.start()
+	}
+	n, err := w.c.Response().Write(p)
+	w.c.Response().Flush()
+	return n, err
+}
+
+func (r *Restapi) ExportProducts(c echo.Context) error {
+	return r.export(c, "products", r.service.ExportProducts)
+}
+
+func (r *Restapi) ExportSales(c echo.Context) error {
+	return r.export(c, "sales", r.service.ExportSales)
+}
+
+func (r *Restapi) export(c echo.Context, filename string, fn func(context.Context, request.Export, io.Writer) (int, error)) error {
+	req := request.Export{}
+	if err := c.Bind(&req); err != nil {
+		return httpHelper.ResponseJSONHTTP(c, http.StatusBadRequest, "", nil, nil, err)
+	}
+	req.UserID = c.Get(common.EncodedUserJwtCtxKey.ToString()).(*response.User).ID
+
+	w := &exportWriter{c: c, filename: filename, format: req.Format}
+	code, err := fn(c.Request().Context(), req, w)
+	if err != nil && w.started {
+		// the status line is already sent, the client sees a truncated file
+		r.log.Error().Err(err).Str("export", filename).Msg("export interrupted")
+		return nil
+	}
+	r.debugError(err)
+	if err != nil {
+		return httpHelper.ResponseJSONHTTP(c, code, "", nil, nil, err)
+	}
+	// an export without rows is still a valid, empty file
+	if !w.started {
+		w.start()
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package restapi
+
+import (
+	"ecomm/internal/helper/common"
+	httpHelper "ecomm/internal/helper/http"
+	"ecomm/internal/model/request"
+	"ecomm/internal/model/response"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (r *Restapi) GetNotifications(c echo.Context) error {
+	req := request.GetNotifications{}
+	if err := c.Bind(&req); err != nil {
+		return httpHelper.ResponseJSONHTTP(c, http.StatusBadRequest, "", nil, nil, err)
+	}
+	req.UserID = c.Get(common.EncodedUserJwtCtxKey.ToString()).(*response.User).ID
+
+	if req.Limit <= 0 {
+		req.Limit = 10
+	}
+	if req.Offset <= 0 {
+		req.Offset = 0
+	}
+
+	notifications, meta, code, err := r.service.GetNotifications(c.Request().Context(), req)
+	r.debugError(err)
+	return httpHelper.ResponseJSONHTTP(c, code, "",
+		map[string]interface{}{
+			"notifications": notifications,
+		}, meta, err)
+}
+
+func (r *Restapi) ReadNotification(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return httpHelper.ResponseJSONHTTP(c, http.StatusBadRequest, "", nil, nil, err)
+	}
+	userId := c.Get(common.EncodedUserJwtCtxKey.ToString()).(*response.User).ID
+
+	code, err := r.service.MarkNotificationRead(c.Request().Context(), int64(id), userId)
+	r.debugError(err)
+	return httpHelper.ResponseJSONHTTP(c, code, "", nil, nil, err)
+}
@@ -0,0 +1,92 @@
+package restapi
+
+import (
+	"ecomm/internal/helper/common"
+	httpHelper "ecomm/internal/helper/http"
+	"ecomm/internal/model/entity"
+	"ecomm/internal/model/request"
+	"ecomm/internal/model/response"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (r *Restapi) GetPayments(c echo.Context) error {
+	req := request.GetPayments{}
+	if err := c.Bind(&req); err != nil {
+		return httpHelper.ResponseJSONHTTP(c, http.StatusBadRequest, "", nil, nil, err)
+	}
+	req.UserID = c.Get(common.EncodedUserJwtCtxKey.ToString()).(*response.User).ID
+
+	if req.Limit <= 0 {
+		req.Limit = 10
+	}
+	if req.Offset <= 0 {
+		req.Offset = 0
+	}
+
+	payments, meta, code, err := r.service.GetPayments(c.Request().Context(), req)
+	r.debugError(err)
+	return httpHelper.ResponseJSONHTTP(c, code, "",
+		map[string]interface{}{
+			"payments": payments,
+		}, meta, err)
+}
+
+func (r *Restapi) GetSales(c echo.Context) error {
+	req := request.GetPayments{}
+	if err := c.Bind(&req); err != nil {
+		return httpHelper.ResponseJSONHTTP(c, http.StatusBadRequest, "", nil, nil, err)
+	}
+	req.SellerID = c.Get(common.EncodedUserJwtCtxKey.ToString()).(*response.User).ID
+
+	if req.Limit <= 0 {
+		req.Limit = 10
+	}
+	if req.Offset <= 0 {
+		req.Offset = 0
+	}
+
+	payments, meta, code, err := r.service.GetPayments(c.Request().Context(), req)
+	r.debugError(err)
+	return httpHelper.ResponseJSONHTTP(c, code, "",
+		map[string]interface{}{
+			"payments": payments,
+		}, meta, err)
+}
+
+func (r *Restapi) ConfirmPayment(c echo.Context) error {
+	return r.updatePaymentStatus(c, entity.PaymentStatusPaid)
+}
+
+func (r *Restapi) RejectPayment(c echo.Context) error {
+	return r.updatePaymentStatus(c, entity.PaymentStatusRejected)
+}
+
+func (r *Restapi) ShipPayment(c echo.Context) error {
+	return r.updatePaymentStatus(c, entity.PaymentStatusShipped)
+}
+
+func (r *Restapi) CompletePayment(c echo.Context) error {
+	return r.updatePaymentStatus(c, entity.PaymentStatusCompleted)
+}
+
+func (r *Restapi) CancelPayment(c echo.Context) error {
+	return r.updatePaymentStatus(c, entity.PaymentStatusCancelled)
+}
+
+func (r *Restapi) updatePaymentStatus(c echo.Context, status string) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return httpHelper.ResponseJSONHTTP(c, http.StatusBadRequest, "", nil, nil, err)
+	}
+
+	payment, code, err := r.service.UpdatePaymentStatus(c.Request().Context(), request.UpdatePaymentStatus{
+		ID:     int64(id),
+		Status: status,
+		UserID: c.Get(common.EncodedUserJwtCtxKey.ToString()).(*response.User).ID,
+	})
+	r.debugError(err)
+	return httpHelper.ResponseJSONHTTP(c, code, "", payment, nil, err)
+}
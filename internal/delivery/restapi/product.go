@@ -1,10 +1,12 @@
 package restapi
 
 import (
+	"bytes"
 	"ecomm/internal/helper/common"
 	httpHelper "ecomm/internal/helper/http"
 	"ecomm/internal/model/request"
 	"ecomm/internal/model/response"
+	"encoding/csv"
 	"net/http"
 	"strconv"
 
@@ -31,6 +33,9 @@ func (r *Restapi) GetProductByID(c echo.Context) error {
 
 	prd, seller, code, err := r.service.GetProductWithSellerByID(c.Request().Context(), int64(id))
 	r.debugError(err)
+	if prd != nil {
+		c.Response().Header().Set("ETag", httpHelper.ETag(prd.Version))
+	}
 	return httpHelper.ResponseJSONHTTP(c, code, "", map[string]interface{}{"product": prd, "seller": seller}, nil, err)
 }
 func (r *Restapi) DeleteProductByID(c echo.Context) error {
@@ -39,7 +44,25 @@ func (r *Restapi) DeleteProductByID(c echo.Context) error {
 		return httpHelper.ResponseJSONHTTP(c, http.StatusBadRequest, "", nil, nil, err)
 	}
 
-	code, err := r.service.DeleteProductByID(c.Request().Context(), int64(id))
+	version, code, err := httpHelper.GetIfMatchVersion(c.Request())
+	if err != nil {
+		return httpHelper.ResponseJSONHTTP(c, code, "", nil, nil, err)
+	}
+	userId := c.Get(common.EncodedUserJwtCtxKey.ToString()).(*response.User).ID
+
+	code, err = r.service.DeleteProductByID(c.Request().Context(), int64(id), version, userId)
+	r.debugError(err)
+	return httpHelper.ResponseJSONHTTP(c, code, "", nil, nil, err)
+}
+
+func (r *Restapi) RestoreProductByID(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return httpHelper.ResponseJSONHTTP(c, http.StatusBadRequest, "", nil, nil, err)
+	}
+	userId := c.Get(common.EncodedUserJwtCtxKey.ToString()).(*response.User).ID
+
+	code, err := r.service.RestoreProductByID(c.Request().Context(), int64(id), userId)
 	r.debugError(err)
 	return httpHelper.ResponseJSONHTTP(c, code, "", nil, nil, err)
 }
@@ -55,8 +78,14 @@ func (r *Restapi) PatchProductByID(c echo.Context) error {
 		return httpHelper.ResponseJSONHTTP(c, http.StatusBadRequest, "", nil, nil, err)
 	}
 	req.ID = int64(id)
+	req.UserID = c.Get(common.EncodedUserJwtCtxKey.ToString()).(*response.User).ID
+	version, code, err := httpHelper.GetIfMatchVersion(c.Request())
+	if err != nil {
+		return httpHelper.ResponseJSONHTTP(c, code, "", nil, nil, err)
+	}
+	req.Version = version
 
-	_, code, err := r.service.UpdateProductByID(c.Request().Context(), req)
+	_, code, err = r.service.UpdateProductByID(c.Request().Context(), req)
 	r.debugError(err)
 	return httpHelper.ResponseJSONHTTP(c, code, "", nil, nil, err)
 }
@@ -81,11 +110,26 @@ func (r *Restapi) GetProducts(c echo.Context) error {
 
 	prd, meta, code, err := r.service.GetProducts(c.Request().Context(), req)
 
+	r.debugError(err)
+	return httpHelper.ResponseJSONHTTP(c, code, "", prd, meta, err)
+}
+
+func (r *Restapi) GetProductSuggestions(c echo.Context) error {
+	req := request.GetSuggestions{}
+	if err := c.Bind(&req); err != nil {
+		return httpHelper.ResponseJSONHTTP(c, http.StatusBadRequest, "", nil, nil, err)
+	}
+
+	if req.Limit <= 0 {
+		req.Limit = 10
+	}
+
+	suggestions, code, err := r.service.GetSuggestions(c.Request().Context(), req)
 	r.debugError(err)
 	return httpHelper.ResponseJSONHTTP(c, code, "",
 		map[string]interface{}{
-			"products": prd,
-		}, meta, err)
+			"suggestions": suggestions,
+		}, nil, err)
 }
 
 func (r *Restapi) PatchProductStockByID(c echo.Context) error {
@@ -99,13 +143,38 @@ func (r *Restapi) PatchProductStockByID(c echo.Context) error {
 		return httpHelper.ResponseJSONHTTP(c, http.StatusBadRequest, "", nil, nil, err)
 	}
 	req.ID = int64(id)
+	req.UserID = c.Get(common.EncodedUserJwtCtxKey.ToString()).(*response.User).ID
+	version, code, err := httpHelper.GetIfMatchVersion(c.Request())
+	if err != nil {
+		return httpHelper.ResponseJSONHTTP(c, code, "", nil, nil, err)
+	}
+	req.Version = version
 
-	code, err := r.service.UpdateProductStockByID(c.Request().Context(), req)
+	code, err = r.service.UpdateProductStockByID(c.Request().Context(), req)
 	r.debugError(err)
 	return httpHelper.ResponseJSONHTTP(c, code, "", nil, nil, err)
 }
 
+func (r *Restapi) PatchProductStocks(c echo.Context) error {
+	req := request.UpdateProductStocks{}
+	if err := c.Bind(&req); err != nil {
+		return httpHelper.ResponseJSONHTTP(c, http.StatusBadRequest, "", nil, nil, err)
+	}
+	req.UserID = c.Get(common.EncodedUserJwtCtxKey.ToString()).(*response.User).ID
+
+	items, code, err := r.service.UpdateProductStocks(c.Request().Context(), req)
+	r.debugError(err)
+	return httpHelper.ResponseJSONHTTP(c, code, "",
+		map[string]interface{}{
+			"items": items,
+		}, nil, err)
+}
+
 func (r *Restapi) PurchaseProduct(c echo.Context) error {
+	if c.QueryParam("mode") == "reserve" {
+		return r.ReserveProduct(c)
+	}
+
 	req := request.PurchaseProduct{}
 	if err := c.Bind(&req); err != nil {
 		return httpHelper.ResponseJSONHTTP(c, http.StatusBadRequest, "", nil, nil, err)
@@ -113,7 +182,75 @@ func (r *Restapi) PurchaseProduct(c echo.Context) error {
 	prdId, _ := strconv.Atoi(c.Param("id"))
 	req.ProductId = int64(prdId)
 	req.UserID = c.Get(common.EncodedUserJwtCtxKey.ToString()).(*response.User).ID
-	code, err := r.service.PurchaseProduct(c.Request().Context(), req)
+	payment, code, err := r.service.PurchaseProduct(c.Request().Context(), req)
 	r.debugError(err)
-	return httpHelper.ResponseJSONHTTP(c, code, "", nil, nil, err)
+	return httpHelper.ResponseJSONHTTP(c, code, "", payment, nil, err)
+}
+
+func (r *Restapi) ReserveProduct(c echo.Context) error {
+	req := request.ReserveProduct{}
+	if err := c.Bind(&req); err != nil {
+		return httpHelper.ResponseJSONHTTP(c, http.StatusBadRequest, "", nil, nil, err)
+	}
+	prdId, _ := strconv.Atoi(c.Param("id"))
+	req.ProductId = int64(prdId)
+	req.UserID = c.Get(common.EncodedUserJwtCtxKey.ToString()).(*response.User).ID
+	reservation, code, err := r.service.ReserveProduct(c.Request().Context(), req)
+	r.debugError(err)
+	return httpHelper.ResponseJSONHTTP(c, code, "", reservation, nil, err)
+}
+
+func (r *Restapi) GetProductHistory(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return httpHelper.ResponseJSONHTTP(c, http.StatusBadRequest, "", nil, nil, err)
+	}
+
+	req := request.GetProductHistory{}
+	if err := c.Bind(&req); err != nil {
+		return httpHelper.ResponseJSONHTTP(c, http.StatusBadRequest, "", nil, nil, err)
+	}
+	req.ProductId = int64(id)
+	req.UserID = c.Get(common.EncodedUserJwtCtxKey.ToString()).(*response.User).ID
+
+	if req.Limit <= 0 {
+		req.Limit = 10
+	}
+	if req.Offset <= 0 {
+		req.Offset = 0
+	}
+
+	versions, meta, code, err := r.service.GetProductHistory(c.Request().Context(), req)
+	r.debugError(err)
+	return httpHelper.ResponseJSONHTTP(c, code, "", map[string]interface{}{"versions": versions}, meta, err)
+}
+
+func (r *Restapi) ImportProducts(c echo.Context) error {
+	req := request.ImportProducts{}
+	if err := (&echo.DefaultBinder{}).BindQueryParams(c, &req); err != nil {
+		return httpHelper.ResponseJSONHTTP(c, http.StatusBadRequest, "", nil, nil, err)
+	}
+	req.UserID = c.Get(common.EncodedUserJwtCtxKey.ToString()).(*response.User).ID
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		return httpHelper.ResponseJSONHTTP(c, http.StatusBadRequest, "", nil, nil, err)
+	}
+
+	res, code, err := r.service.ImportProducts(c.Request().Context(), req, file)
+	r.debugError(err)
+	if err != nil || req.Report != "csv" {
+		return httpHelper.ResponseJSONHTTP(c, code, "", res, nil, err)
+	}
+
+	var report bytes.Buffer
+	w := csv.NewWriter(&report)
+	w.Write([]string{"line", "name", "status", "productId", "error"})
+	for _, row := range res.Rows {
+		w.Write([]string{strconv.Itoa(row.Line), row.Name, row.Status, row.ProductID, row.Error})
+	}
+	w.Flush()
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="product-import-report.csv"`)
+	return c.Blob(code, "text/csv", report.Bytes())
 }
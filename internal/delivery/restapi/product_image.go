@@ -0,0 +1,64 @@
+package restapi
+
+import (
+	"ecomm/internal/helper/common"
+	httpHelper "ecomm/internal/helper/http"
+	"ecomm/internal/model/request"
+	"ecomm/internal/model/response"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (r *Restapi) AddProductImage(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return httpHelper.ResponseJSONHTTP(c, http.StatusBadRequest, "", nil, nil, err)
+	}
+
+	req := request.AddProductImage{}
+	if err := c.Bind(&req); err != nil {
+		return httpHelper.ResponseJSONHTTP(c, http.StatusBadRequest, "", nil, nil, err)
+	}
+	req.ProductId = int64(id)
+	req.UserID = c.Get(common.EncodedUserJwtCtxKey.ToString()).(*response.User).ID
+
+	images, code, err := r.service.AddProductImage(c.Request().Context(), req)
+	r.debugError(err)
+	return httpHelper.ResponseJSONHTTP(c, code, "", map[string]interface{}{"images": images}, nil, err)
+}
+
+func (r *Restapi) ReorderProductImages(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return httpHelper.ResponseJSONHTTP(c, http.StatusBadRequest, "", nil, nil, err)
+	}
+
+	req := request.ReorderProductImages{}
+	if err := c.Bind(&req); err != nil {
+		return httpHelper.ResponseJSONHTTP(c, http.StatusBadRequest, "", nil, nil, err)
+	}
+	req.ProductId = int64(id)
+	req.UserID = c.Get(common.EncodedUserJwtCtxKey.ToString()).(*response.User).ID
+
+	images, code, err := r.service.ReorderProductImages(c.Request().Context(), req)
+	r.debugError(err)
+	return httpHelper.ResponseJSONHTTP(c, code, "", map[string]interface{}{"images": images}, nil, err)
+}
+
+func (r *Restapi) DeleteProductImage(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return httpHelper.ResponseJSONHTTP(c, http.StatusBadRequest, "", nil, nil, err)
+	}
+	imageId, err := strconv.Atoi(c.Param("imageId"))
+	if err != nil {
+		return httpHelper.ResponseJSONHTTP(c, http.StatusBadRequest, "", nil, nil, err)
+	}
+
+	userId := c.Get(common.EncodedUserJwtCtxKey.ToString()).(*response.User).ID
+	images, code, err := r.service.DeleteProductImage(c.Request().Context(), int64(id), int64(imageId), userId)
+	r.debugError(err)
+	return httpHelper.ResponseJSONHTTP(c, code, "", map[string]interface{}{"images": images}, nil, err)
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/labstack/echo/v4"
+	echoMiddleware "github.com/labstack/echo/v4/middleware"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -30,16 +31,56 @@ func (r *Restapi) MakeRoute(e *echo.Echo) {
 	NewRoute(e, http.MethodPost, "/v1/image", r.UploadImage, r.middleware.Authentication(true))
 
 	// product
-	NewRoute(e, http.MethodPost, "/v1/product", r.CreateProduct, r.middleware.Authentication(true))
+	NewRoute(e, http.MethodPost, "/v1/product", r.CreateProduct, r.middleware.Authentication(true), r.middleware.Idempotency)
 	NewRoute(e, http.MethodGet, "/v1/product", r.GetProducts, r.middleware.Authentication(false))
+	NewRoute(e, http.MethodGet, "/v1/product/suggest", r.GetProductSuggestions)
+	// the body limit leaves room for the multipart envelope around the largest accepted file
+	NewRoute(e, http.MethodPost, "/v1/product/import", r.ImportProducts, echoMiddleware.BodyLimit("6M"), r.middleware.Authentication(true), r.middleware.Idempotency)
+	NewRoute(e, http.MethodGet, "/v1/product/export", r.ExportProducts, r.middleware.Authentication(true))
+	// ownership and version of every product of the batch are checked by the repository in one query
+	NewRoute(e, http.MethodPatch, "/v1/product/stock", r.PatchProductStocks, r.middleware.Authentication(true))
 	NewRoute(e, http.MethodGet, "/v1/product/:id", r.GetProductByID)
 	NewRoute(e, http.MethodDelete, "/v1/product/:id", r.DeleteProductByID, r.middleware.Authentication(true), r.middleware.IsProductOwner)
+	// deleted products are invisible to IsProductOwner, ownership is checked by the service
+	NewRoute(e, http.MethodPost, "/v1/product/:id/restore", r.RestoreProductByID, r.middleware.Authentication(true))
+	NewRoute(e, http.MethodGet, "/v1/product/:id/history", r.GetProductHistory, r.middleware.Authentication(true))
 	NewRoute(e, http.MethodPatch, "/v1/product/:id", r.PatchProductByID, r.middleware.Authentication(true), r.middleware.IsProductOwner)
 	NewRoute(e, http.MethodPatch, "/v1/product/:id/stock", r.PatchProductStockByID, r.middleware.Authentication(true), r.middleware.IsProductOwner)
-	NewRoute(e, http.MethodPost, "/v1/product/:id/buy", r.PurchaseProduct, r.middleware.Authentication(true))
+	NewRoute(e, http.MethodPost, "/v1/product/:id/images", r.AddProductImage, r.middleware.Authentication(true), r.middleware.IsProductOwner)
+	NewRoute(e, http.MethodPatch, "/v1/product/:id/images/order", r.ReorderProductImages, r.middleware.Authentication(true), r.middleware.IsProductOwner)
+	NewRoute(e, http.MethodDelete, "/v1/product/:id/images/:imageId", r.DeleteProductImage, r.middleware.Authentication(true), r.middleware.IsProductOwner)
+	NewRoute(e, http.MethodPost, "/v1/product/:id/buy", r.PurchaseProduct, r.middleware.Authentication(true), r.middleware.Idempotency)
+	NewRoute(e, http.MethodPost, "/v1/product/:id/price-alert", r.SubscribePriceAlert, r.middleware.Authentication(true))
+	NewRoute(e, http.MethodDelete, "/v1/product/:id/price-alert", r.UnsubscribePriceAlert, r.middleware.Authentication(true))
+	// notification
+	NewRoute(e, http.MethodGet, "/v1/notification", r.GetNotifications, r.middleware.Authentication(true))
+	NewRoute(e, http.MethodPost, "/v1/notification/:id/read", r.ReadNotification, r.middleware.Authentication(true))
+	// tag
+	NewRoute(e, http.MethodGet, "/v1/tags", r.GetTags)
+	// category
+	NewRoute(e, http.MethodGet, "/v1/category", r.GetCategories)
+	NewRoute(e, http.MethodPost, "/v1/category", r.CreateCategory, r.middleware.Authentication(true), r.middleware.IsAdmin)
+	NewRoute(e, http.MethodPatch, "/v1/category/:id", r.PatchCategoryByID, r.middleware.Authentication(true), r.middleware.IsAdmin)
+	NewRoute(e, http.MethodDelete, "/v1/category/:id", r.DeleteCategoryByID, r.middleware.Authentication(true), r.middleware.IsAdmin)
+	// cart
+	NewRoute(e, http.MethodGet, "/v1/cart", r.GetCart, r.middleware.Authentication(true))
+	NewRoute(e, http.MethodPost, "/v1/cart/items", r.AddCartItem, r.middleware.Authentication(true))
+	NewRoute(e, http.MethodPatch, "/v1/cart/items/:productId", r.PatchCartItem, r.middleware.Authentication(true))
+	NewRoute(e, http.MethodDelete, "/v1/cart/items/:productId", r.DeleteCartItem, r.middleware.Authentication(true))
+	NewRoute(e, http.MethodPost, "/v1/cart/checkout", r.Checkout, r.middleware.Authentication(true), r.middleware.Idempotency)
+	// payment
+	NewRoute(e, http.MethodGet, "/v1/payment", r.GetPayments, r.middleware.Authentication(true))
+	NewRoute(e, http.MethodGet, "/v1/payment/sales", r.GetSales, r.middleware.Authentication(true))
+	NewRoute(e, http.MethodGet, "/v1/payment/sales/export", r.ExportSales, r.middleware.Authentication(true))
+	NewRoute(e, http.MethodPost, "/v1/payment/:id/confirm", r.ConfirmPayment, r.middleware.Authentication(true), r.middleware.IsPaymentSeller)
+	NewRoute(e, http.MethodPost, "/v1/payment/:id/reject", r.RejectPayment, r.middleware.Authentication(true), r.middleware.IsPaymentSeller)
+	NewRoute(e, http.MethodPost, "/v1/payment/:id/ship", r.ShipPayment, r.middleware.Authentication(true), r.middleware.IsPaymentSeller)
+	NewRoute(e, http.MethodPost, "/v1/payment/:id/complete", r.CompletePayment, r.middleware.Authentication(true), r.middleware.IsPaymentBuyer)
+	NewRoute(e, http.MethodPost, "/v1/payment/:id/cancel", r.CancelPayment, r.middleware.Authentication(true), r.middleware.IsPaymentBuyer)
 	// bank
 	NewRoute(e, http.MethodPost, "/v1/bank/account", r.CreateBank, r.middleware.Authentication(true))
 	NewRoute(e, http.MethodGet, "/v1/bank/account", r.GetBanks, r.middleware.Authentication(false))
+	NewRoute(e, http.MethodGet, "/v1/bank/account/:id", r.GetBankByID, r.middleware.Authentication(true), r.middleware.IsBankOwner)
 	NewRoute(e, http.MethodDelete, "/v1/bank/account/:id", r.DeleteBankByID, r.middleware.Authentication(true), r.middleware.IsBankOwner)
 	NewRoute(e, http.MethodPatch, "/v1/bank/account/:id", r.PatchBankByID, r.middleware.Authentication(true), r.middleware.IsBankOwner)
 }
@@ -0,0 +1,27 @@
+package restapi
+
+import (
+	httpHelper "ecomm/internal/helper/http"
+	"ecomm/internal/model/request"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (r *Restapi) GetTags(c echo.Context) error {
+	req := request.GetTags{}
+	if err := c.Bind(&req); err != nil {
+		return httpHelper.ResponseJSONHTTP(c, http.StatusBadRequest, "", nil, nil, err)
+	}
+
+	if req.Limit <= 0 {
+		req.Limit = 20
+	}
+
+	tags, code, err := r.service.GetTags(c.Request().Context(), req)
+	r.debugError(err)
+	return httpHelper.ResponseJSONHTTP(c, code, "",
+		map[string]interface{}{
+			"tags": tags,
+		}, nil, err)
+}
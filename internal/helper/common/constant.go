@@ -21,5 +21,8 @@ type UserClaims struct {
 type Meta struct {
 	Limit  int
 	Offset int
-	Total  int
+	// Total is left empty when paginating by cursor
+	Total int
+	// NextCursor resumes the listing after the current page, empty on the last page
+	NextCursor string `json:",omitempty"`
 }
@@ -15,8 +15,27 @@ var (
 	ErrEmailExist       = errors.New("email already exist")
 	ErrUnauthorized     = errors.New("unauthorized")
 	ErrForbidden        = errors.New("forbidden")
+	ErrConflict         = errors.New("conflict")
+	// ErrPreconditionFailed is returned when the If-Match version is not the current one anymore
+	ErrPreconditionFailed = errors.New("precondition failed")
+	// ErrPreconditionRequired is returned when a request that needs If-Match does not send it
+	ErrPreconditionRequired = errors.New("precondition required")
 )
 
+// RejectionError is a request refused by a business rule, with a machine-readable reason
+type RejectionError struct {
+	Reason  string
+	Message string
+}
+
+func (e *RejectionError) Error() string {
+	return e.Message
+}
+
+func ErrRejected(reason string, message string) error {
+	return &RejectionError{Reason: reason, Message: message}
+}
+
 func ErrInputRequest(err error) error {
 	return fmt.Errorf("input request error: %s", err.Error())
 }
@@ -34,6 +53,12 @@ func HTTPCodeFromError(err error) int {
 		return http.StatusBadRequest
 	} else if err == ErrUnauthorized {
 		return http.StatusUnauthorized
+	} else if err == ErrConflict {
+		return http.StatusConflict
+	} else if err == ErrPreconditionFailed {
+		return http.StatusPreconditionFailed
+	} else if err == ErrPreconditionRequired {
+		return http.StatusPreconditionRequired
 	} else {
 		return http.StatusInternalServerError
 	}
@@ -2,7 +2,9 @@ package util
 
 import (
 	"ecomm/internal/helper/common"
+	"ecomm/internal/helper/errorer"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/labstack/echo/v4"
@@ -16,6 +18,9 @@ func ResponseJSONHTTP(c echo.Context, code int, msg string, data interface{}, me
 	}
 	if err != nil {
 		res["message"] = errors.Cause(err).Error()
+		if rejection, ok := errors.Cause(err).(*errorer.RejectionError); ok {
+			res["reason"] = rejection.Reason
+		}
 	} else {
 		if msg != "" {
 			res["message"] = msg
@@ -50,3 +55,23 @@ func GetJWTFromRequest(r *http.Request) string {
 
 	return cookie.Value
 }
+
+// ETag formats the version of a resource as a strong entity tag
+func ETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// GetIfMatchVersion reads the version sent back in the If-Match header. A missing header is
+// refused with 428, anything but a single ETag returned by this API is a bad request
+func GetIfMatchVersion(r *http.Request) (int, int, error) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" {
+		return 0, http.StatusPreconditionRequired, errors.Wrap(errorer.ErrPreconditionRequired, "If-Match header is required")
+	}
+
+	version, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(header, `"`), `"`))
+	if err != nil || len(header) < 3 || header[0] != '"' || header[len(header)-1] != '"' {
+		return 0, http.StatusBadRequest, errors.Wrap(errorer.ErrInputRequest(errors.New("invalid If-Match header")), "invalid If-Match header")
+	}
+	return version, http.StatusOK, nil
+}
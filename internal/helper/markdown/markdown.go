@@ -0,0 +1,143 @@
+// Package markdown renders the restricted Markdown subset allowed in product descriptions.
+//
+// Supported: paragraphs, headings (# to ###), bullet and numbered lists, **bold**, *italic*,
+// `code` and [links](https://example.com). Everything else, raw HTML included, is rendered as
+// escaped text, so the output only ever contains the tags emitted here.
+package markdown
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var (
+	headingPattern     = regexp.MustCompile(`^(#{1,3})\s+(.*)$`)
+	bulletItemPattern  = regexp.MustCompile(`^[-*+]\s+(.*)$`)
+	orderedItemPattern = regexp.MustCompile(`^\d{1,9}[.)]\s+(.*)$`)
+)
+
+// allowedLinkSchemes are the only link targets rendered as anchors
+var allowedLinkSchemes = map[string]bool{
+	"http":   true,
+	"https":  true,
+	"mailto": true,
+}
+
+// ToHTML renders the Markdown source to sanitized HTML
+func ToHTML(source string) string {
+	var b strings.Builder
+	var paragraph []string
+	listTag := ""
+
+	flushParagraph := func() {
+		if len(paragraph) > 0 {
+			b.WriteString("<p>" + renderInline(strings.Join(paragraph, " ")) + "</p>\n")
+			paragraph = nil
+		}
+	}
+	closeList := func() {
+		if listTag != "" {
+			b.WriteString("</" + listTag + ">\n")
+			listTag = ""
+		}
+	}
+	listItem := func(tag string, text string) {
+		flushParagraph()
+		if listTag != tag {
+			closeList()
+			b.WriteString("<" + tag + ">\n")
+			listTag = tag
+		}
+		b.WriteString("<li>" + renderInline(text) + "</li>\n")
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(source, "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+			flushParagraph()
+			closeList()
+		case headingPattern.MatchString(line):
+			flushParagraph()
+			closeList()
+			m := headingPattern.FindStringSubmatch(line)
+			// h1 is reserved for the product name, so headings start at h2
+			tag := "h" + string(rune('1'+len(m[1])))
+			b.WriteString("<" + tag + ">" + renderInline(m[2]) + "</" + tag + ">\n")
+		case bulletItemPattern.MatchString(line):
+			listItem("ul", bulletItemPattern.FindStringSubmatch(line)[1])
+		case orderedItemPattern.MatchString(line):
+			listItem("ol", orderedItemPattern.FindStringSubmatch(line)[1])
+		default:
+			closeList()
+			paragraph = append(paragraph, line)
+		}
+	}
+	flushParagraph()
+	closeList()
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// renderInline renders emphasis, code spans and links of a single block, escaping everything else
+func renderInline(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		switch {
+		case s[i] == '\\' && i+1 < len(s) && strings.IndexByte("\\`*_[]()#+-.!", s[i+1]) >= 0:
+			b.WriteString(html.EscapeString(s[i+1 : i+2]))
+			i += 2
+			continue
+		case s[i] == '`':
+			if end := strings.IndexByte(s[i+1:], '`'); end > 0 {
+				b.WriteString("<code>" + html.EscapeString(s[i+1:i+1+end]) + "</code>")
+				i += end + 2
+				continue
+			}
+		case strings.HasPrefix(s[i:], "**"):
+			if end := strings.Index(s[i+2:], "**"); end > 0 {
+				b.WriteString("<strong>" + renderInline(s[i+2:i+2+end]) + "</strong>")
+				i += end + 4
+				continue
+			}
+		case s[i] == '*' || s[i] == '_':
+			if end := strings.IndexByte(s[i+1:], s[i]); end > 0 {
+				b.WriteString("<em>" + renderInline(s[i+1:i+1+end]) + "</em>")
+				i += end + 2
+				continue
+			}
+		case s[i] == '[':
+			if text, href, n, ok := parseLink(s[i:]); ok {
+				b.WriteString(`<a href="` + html.EscapeString(href) + `" rel="nofollow noopener noreferrer">` + renderInline(text) + "</a>")
+				i += n
+				continue
+			}
+		}
+		b.WriteString(html.EscapeString(s[i : i+1]))
+		i++
+	}
+	return b.String()
+}
+
+// parseLink reads a [text](href) link at the start of s, returning its length.
+// Links to schemes other than the allowed ones are not recognised and stay plain text
+func parseLink(s string) (text string, href string, n int, ok bool) {
+	textEnd := strings.Index(s, "](")
+	if textEnd <= 1 {
+		return "", "", 0, false
+	}
+	hrefEnd := strings.IndexByte(s[textEnd+2:], ')')
+	if hrefEnd <= 0 {
+		return "", "", 0, false
+	}
+
+	text = s[1:textEnd]
+	href = strings.TrimSpace(s[textEnd+2 : textEnd+2+hrefEnd])
+	u, err := url.Parse(href)
+	if err != nil || !allowedLinkSchemes[strings.ToLower(u.Scheme)] || strings.ContainsAny(href, " \t\"'<>") {
+		return "", "", 0, false
+	}
+	return text, href, textEnd + 2 + hrefEnd + 1, true
+}
@@ -9,4 +9,6 @@ type Bank struct {
 	User          User
 	CreatedAt     int64
 	UpdatedAt     int64
+	// Version is incremented on every update, it is sent as the ETag of the bank
+	Version int
 }
@@ -0,0 +1,12 @@
+package entity
+
+// CartItem represents a product in a user's shopping cart
+type CartItem struct {
+	ID        int64
+	UserID    int64
+	ProductID int64
+	Quantity  int
+	Product   Product
+	CreatedAt int64
+	UpdatedAt int64
+}
@@ -0,0 +1,10 @@
+package entity
+
+// Category is a node of the product category tree, ParentID is 0 for top level categories
+type Category struct {
+	ID        int64
+	Name      string
+	ParentID  int64
+	CreatedAt int64
+	UpdatedAt int64
+}
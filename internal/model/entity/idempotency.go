@@ -0,0 +1,20 @@
+package entity
+
+// IdempotencyKey records a client supplied key with the request it was first used for
+// and the response that request produced
+type IdempotencyKey struct {
+	ID           int64
+	UserID       int64
+	Key          string
+	RequestHash  string
+	ResponseCode int
+	ResponseBody string
+	// ResponseContentType and ResponseContentDisposition are the headers needed to replay a file download
+	ResponseContentType        string
+	ResponseContentDisposition string
+	// Completed is false while the first request with the key is still being processed
+	Completed bool
+	ExpiresAt int64
+	CreatedAt int64
+	UpdatedAt int64
+}
@@ -0,0 +1,24 @@
+package entity
+
+const (
+	NotificationTypePriceDrop = "price_drop"
+)
+
+// Notification is an in-app message for a user, ProductID is 0 when it is not about a product
+type Notification struct {
+	ID        int64
+	UserID    int64
+	Type      string
+	ProductID int64
+	Message   string
+	// ReadAt is 0 until the user reads the notification
+	ReadAt    int64
+	CreatedAt int64
+}
+
+type GetAllNotificationFilter struct {
+	UserID     int64
+	UnreadOnly bool
+	Limit      int
+	Offset     int
+}
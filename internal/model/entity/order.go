@@ -0,0 +1,15 @@
+package entity
+
+// Order groups the payments of a checkout that go to a single seller
+type Order struct {
+	ID                   int64
+	UserID               int64
+	SellerID             int64
+	BankID               int64
+	PaymentProofImageURL string
+	TotalPrice           int
+	Items                []CartItem
+	Payments             []Payment
+	CreatedAt            int64
+	UpdatedAt            int64
+}
@@ -0,0 +1,54 @@
+package entity
+
+const (
+	PaymentStatusPendingVerification = "pending_verification"
+	PaymentStatusPaid                = "paid"
+	PaymentStatusShipped             = "shipped"
+	PaymentStatusCompleted           = "completed"
+	PaymentStatusRejected            = "rejected"
+	PaymentStatusCancelled           = "cancelled"
+)
+
+// Rejection reasons of the purchase rules that are checked again by the repository while the product row is locked
+const (
+	PurchaseRejectedQuantityExceeded = "quantity_limit_exceeded"
+	PurchaseRejectedBuyerLimit       = "buyer_limit_exceeded"
+)
+
+// Payment represents a purchase (order) record in the database
+type Payment struct {
+	ID                   int64
+	OrderID              int64
+	UserID               int64
+	SellerID             int64
+	ProductID            int64
+	BankID               int64
+	Quantity             int
+	PaymentProofImageURL string
+	Status               string
+	// snapshot of the product at purchase time
+	ProductName     string
+	ProductPrice    int
+	ProductImageURL string
+	// ProductVersion is the version of the product history the buyer saw, 0 for payments made before versioning
+	ProductVersion int
+	// purchased variant of the product, if any
+	VariantID  int64
+	VariantSKU string
+	Bank       Bank
+	// reservation consumed by the purchase, if any
+	ReservationID int64
+	CreatedAt     int64
+	UpdatedAt     int64
+}
+
+type GetAllPaymentFilter struct {
+	UserID    int64
+	SellerID  int64
+	ProductID int64
+	Status    string
+	StartDate int64
+	EndDate   int64
+	Limit     int
+	Offset    int
+}
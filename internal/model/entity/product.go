@@ -1,20 +1,64 @@
 package entity
 
+const (
+	// TagMatchAll keeps products having every requested tag
+	TagMatchAll = "all"
+	// TagMatchAny keeps products having at least one of the requested tags
+	TagMatchAny = "any"
+)
+
 // Product represents a product entity in the database
 type Product struct {
-	ID            int64
-	Name          string
+	ID   int64
+	Name string
+	// Description is the raw Markdown written by the seller
+	Description   string
 	Price         int
 	ImageURL      string
 	Stock         int
 	Condition     string
-	Tags          string
+	Tags          []string
+	CategoryID    int64
 	IsPurchasable bool
 	PurchaseCount int
+	// PurchaseLimit is the most units a single buyer may purchase, 0 means unlimited
+	PurchaseLimit int
 	UserID        int64
 	User          User
 	CreatedAt     int64
 	UpdatedAt     int64
+	// Version is the latest entry of the product history
+	Version int
+	// HasVariants tells that the product is sold through its variants only
+	HasVariants bool
+	Options     []ProductOption
+	Variants    []ProductVariant
+	// Images is the gallery in order, ImageURL is a copy of the first image url
+	Images []ProductImage
+	// Highlight is the matched text with search terms wrapped in <mark> tags, only set when searching
+	Highlight string
+}
+
+// ProductOption is a dimension a product varies by, like size or color
+type ProductOption struct {
+	Name   string
+	Values []string
+}
+
+// ProductVariant is a purchasable combination of option values with its own stock
+type ProductVariant struct {
+	ID        int64
+	ProductID int64
+	SKU       string
+	// OptionValues follows the order of the product options
+	OptionValues []string
+	// Price overrides the product price when set
+	Price *int
+	Stock int
+	// ImageURL overrides the product image when not empty
+	ImageURL  string
+	CreatedAt int64
+	UpdatedAt int64
 }
 
 type GetAllProductFilter struct {
@@ -23,11 +67,63 @@ type GetAllProductFilter struct {
 	Limit          int
 	Offset         int
 	Tags           []string
+	TagMatch       string
 	Condition      string
+	CategoryID     int64
 	ShowEmptyStock bool
 	MaxPrice       float64
 	MinPrice       float64
-	SortBy         string
-	OrderBy        string
+	Sorts          []ProductSort
 	Search         string
+	// Cursor continues a previous page instead of using Offset
+	Cursor string
+}
+
+const (
+	ProductSortDate          = "date"
+	ProductSortPrice         = "price"
+	ProductSortRelevance     = "relevance"
+	ProductSortPurchaseCount = "purchaseCount"
+	ProductSortStock         = "stock"
+	ProductSortName          = "name"
+	ProductSortTrending      = "trending"
+)
+
+// ProductSort orders the product listing by one field, earlier sorts take precedence
+type ProductSort struct {
+	Field string
+	Desc  bool
+}
+
+const (
+	FacetCondition = "condition"
+	FacetTags      = "tags"
+	FacetPrice     = "price"
+	FacetStock     = "stock"
+)
+
+// FacetCount is the number of products sharing a facet value
+type FacetCount struct {
+	Value string
+	Count int
+}
+
+// PriceBucket is the number of products priced between Min and Max inclusive
+type PriceBucket struct {
+	Min   int
+	Max   int
+	Count int
+}
+
+type StockFacet struct {
+	InStock    int
+	OutOfStock int
+}
+
+// ProductFacets holds the requested facets of a product listing, facets not requested are left empty
+type ProductFacets struct {
+	Conditions   []FacetCount
+	Tags         []FacetCount
+	PriceBuckets []PriceBucket
+	Stock        *StockFacet
 }
@@ -0,0 +1,11 @@
+package entity
+
+// ProductImage is one image of a product gallery, the image at the lowest position is the cover
+type ProductImage struct {
+	ID        int64
+	ProductID int64
+	URL       string
+	AltText   string
+	Position  int
+	CreatedAt int64
+}
@@ -0,0 +1,16 @@
+package entity
+
+// ProductPrice is an entry of the product price history, recorded when the product is created and on every price change
+type ProductPrice struct {
+	Price     int
+	CreatedAt int64
+}
+
+// PriceAlert notifies the buyer once the product price drops below TargetPrice, it is removed after notifying
+type PriceAlert struct {
+	ID          int64
+	ProductID   int64
+	UserID      int64
+	TargetPrice int
+	CreatedAt   int64
+}
@@ -0,0 +1,21 @@
+package entity
+
+// ProductStockUpdate is one item of a batch stock update, a nil Price keeps the current price.
+// Version is the version of the product the update was made on
+type ProductStockUpdate struct {
+	ProductID int64
+	Stock     int
+	Price     *int
+	Version   int
+}
+
+// ProductStockUpdateResult is the outcome of one item of a batch stock update. Code and Err tell why the
+// item was refused, a refused item rolls back the whole batch
+type ProductStockUpdateResult struct {
+	ProductID int64
+	Stock     int
+	Price     int
+	Version   int
+	Code      int
+	Err       error
+}
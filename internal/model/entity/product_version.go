@@ -0,0 +1,29 @@
+package entity
+
+const (
+	ProductVersionActionCreate  = "create"
+	ProductVersionActionUpdate  = "update"
+	ProductVersionActionStock   = "stock"
+	ProductVersionActionDelete  = "delete"
+	ProductVersionActionRestore = "restore"
+)
+
+// ProductVersion is an entry of the product history, recorded on every change made by the seller.
+// Purchases decrease the stock without adding a version, payments reference the version they were made on
+type ProductVersion struct {
+	ID        int64
+	ProductID int64
+	Version   int
+	Action    string
+	// Snapshot is the JSON listing of the product right after the change
+	Snapshot  []byte
+	UserID    int64
+	CreatedAt int64
+}
+
+type GetProductVersionFilter struct {
+	ProductID int64
+	UserID    int64
+	Limit     int
+	Offset    int
+}
@@ -0,0 +1,19 @@
+package entity
+
+const (
+	ReservationStatusActive   = "active"
+	ReservationStatusConsumed = "consumed"
+	ReservationStatusReleased = "released"
+)
+
+// Reservation represents a time-limited hold on product stock
+type Reservation struct {
+	ID        int64
+	UserID    int64
+	ProductID int64
+	Quantity  int
+	Status    string
+	ExpiresAt int64
+	CreatedAt int64
+	UpdatedAt int64
+}
@@ -0,0 +1,18 @@
+package entity
+
+const (
+	SuggestionKindProduct = "product"
+	SuggestionKindTag     = "tag"
+)
+
+// Suggestion is a search completion matched against product names or tags
+type Suggestion struct {
+	Term  string
+	Kind  string
+	Score float64
+}
+
+type GetSuggestionFilter struct {
+	Query string
+	Limit int
+}
@@ -0,0 +1,12 @@
+package entity
+
+// Tag is a product tag with the number of products using it
+type Tag struct {
+	Name  string
+	Count int
+}
+
+type GetAllTagFilter struct {
+	Prefix string
+	Limit  int
+}
@@ -1,11 +1,14 @@
 package entity
 
 type User struct {
-	ID        int64
-	Name      string
-	Username  string
-	Password  string
-	Banks     []Bank
-	CreatedAt int64
-	UpdatedAt int64
+	ID       int64
+	Name     string
+	Username string
+	Password string
+	// IsSuspended sellers cannot sell their products
+	IsSuspended bool
+	IsAdmin     bool
+	Banks       []Bank
+	CreatedAt   int64
+	UpdatedAt   int64
 }
@@ -13,4 +13,6 @@ type UpdateBank struct {
 	AccountName   string `json:"bankAccountName" validate:"required,min=5,max=15"`
 	AccountNumber string `json:"bankAccountNumber" validate:"required,min=5,max=15"`
 	UserID        int64
+	// Version comes from the If-Match header
+	Version int
 }
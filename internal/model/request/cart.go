@@ -0,0 +1,24 @@
+package request
+
+type AddCartItem struct {
+	ProductId string `json:"productId" validate:"required,numeric"`
+	Quantity  int    `json:"quantity" validate:"required,min=1"`
+	UserID    int64
+}
+
+type UpdateCartItem struct {
+	ProductId int64 `validate:"required"`
+	Quantity  int   `json:"quantity" validate:"required,min=1"`
+	UserID    int64
+}
+
+type Checkout struct {
+	Payments []CheckoutPayment `json:"payments" validate:"required,min=1,dive"`
+	UserID   int64
+}
+
+type CheckoutPayment struct {
+	SellerId             string `json:"sellerId" validate:"required,numeric"`
+	BankAccountId        string `json:"bankAccountId" validate:"required,numeric"`
+	PaymentProofImageUrl string `json:"paymentProofImageUrl" validate:"required,url"`
+}
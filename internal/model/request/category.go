@@ -0,0 +1,12 @@
+package request
+
+type Category struct {
+	Name     string `json:"name" validate:"required,min=2,max=50"`
+	ParentId string `json:"parentId" validate:"omitempty,numeric"`
+}
+
+type UpdateCategory struct {
+	ID       int64  `validate:"required"`
+	Name     string `json:"name" validate:"required,min=2,max=50"`
+	ParentId string `json:"parentId" validate:"omitempty,numeric"`
+}
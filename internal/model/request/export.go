@@ -0,0 +1,7 @@
+package request
+
+type Export struct {
+	UserID int64
+	// Format is the file format of the export, one record per row or per line
+	Format string `query:"format" validate:"required,oneof=csv ndjson"`
+}
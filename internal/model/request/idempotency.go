@@ -0,0 +1,12 @@
+package request
+
+type IdempotencyKey struct {
+	UserID       int64  `validate:"required"`
+	Key          string `validate:"required,max=255"`
+	RequestHash  string `validate:"required"`
+	ResponseCode int
+	ResponseBody string
+	// ResponseContentType and ResponseContentDisposition let non JSON responses, like file downloads, be replayed as sent
+	ResponseContentType        string
+	ResponseContentDisposition string
+}
@@ -0,0 +1,8 @@
+package request
+
+type GetNotifications struct {
+	UserID     int64
+	UnreadOnly bool `query:"unreadOnly"`
+	Limit      int  `query:"limit" default:"10"`
+	Offset     int  `query:"offset" default:"0"`
+}
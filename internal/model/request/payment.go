@@ -0,0 +1,18 @@
+package request
+
+type GetPayments struct {
+	UserID    int64
+	SellerID  int64
+	Limit     int    `query:"limit" default:"10"`
+	Offset    int    `query:"offset" default:"0"`
+	ProductID int64  `query:"productId"`
+	Status    string `query:"status" validate:"omitempty,oneof=pending_verification paid shipped completed rejected cancelled"`
+	StartDate int64  `query:"startDate" validate:"min=0"`
+	EndDate   int64  `query:"endDate" validate:"omitempty,gtefield=StartDate"`
+}
+
+type UpdatePaymentStatus struct {
+	ID     int64  `validate:"required"`
+	Status string `validate:"required"`
+	UserID int64
+}
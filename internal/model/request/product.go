@@ -1,29 +1,64 @@
 package request
 
 type Product struct {
-	Name          string   `json:"name" validate:"required,min=5,max=60"`
-	Price         int      `json:"price" validate:"required,min=0"`
-	ImageURL      string   `json:"imageUrl" validate:"required,url"`
-	Stock         int      `json:"stock" validate:"required,min=0"`
-	Condition     string   `json:"condition" validate:"required"`
-	Tags          []string `json:"tags" validate:"required,min=1,max=5"`
-	IsPurchasable bool     `json:"isPurchasable"`
+	Name string `json:"name" validate:"required,min=5,max=60"`
+	// Description accepts the Markdown subset rendered by the markdown helper
+	Description   string           `json:"description" validate:"max=5000"`
+	Price         int              `json:"price" validate:"required,min=0"`
+	ImageURL      string           `json:"imageUrl" validate:"required,url"`
+	Stock         int              `json:"stock" validate:"required,min=0"`
+	Condition     string           `json:"condition" validate:"required"`
+	Tags          []string         `json:"tags" validate:"required,min=1,max=5,dive,required,max=60"`
+	CategoryId    string           `json:"categoryId" validate:"required,numeric"`
+	IsPurchasable bool             `json:"isPurchasable"`
+	PurchaseLimit int              `json:"purchaseLimitPerBuyer" validate:"min=0"`
+	Options       []ProductOption  `json:"options" validate:"max=3,dive"`
+	Variants      []ProductVariant `json:"variants" validate:"max=100,dive"`
 	UserID        int64
 }
 
 type UpdateProduct struct {
-	ID            int64    `json:"id" validate:"required"`
-	Name          string   `json:"name" validate:"required,min=5,max=60"`
-	Price         int      `json:"price" validate:"required,min=0"`
-	ImageURL      string   `json:"imageUrl" validate:"required,url"`
-	Condition     string   `json:"condition" validate:"required"`
-	Tags          []string `json:"tags" validate:"required,min=1,max=5"`
-	IsPurchasable bool     `json:"isPurchasable"`
+	ID   int64  `json:"id" validate:"required"`
+	Name string `json:"name" validate:"required,min=5,max=60"`
+	// Description accepts the Markdown subset rendered by the markdown helper
+	Description   string          `json:"description" validate:"max=5000"`
+	Price         int             `json:"price" validate:"required,min=0"`
+	ImageURL      string          `json:"imageUrl" validate:"required,url"`
+	Condition     string          `json:"condition" validate:"required"`
+	Tags          []string        `json:"tags" validate:"required,min=1,max=5,dive,required,max=60"`
+	CategoryId    string          `json:"categoryId" validate:"required,numeric"`
+	IsPurchasable bool            `json:"isPurchasable"`
+	PurchaseLimit int             `json:"purchaseLimitPerBuyer" validate:"min=0"`
+	Options       []ProductOption `json:"options" validate:"max=3,dive"`
+	// Variants are matched by SKU, the stock of existing variants is only changed through the stock endpoint.
+	// Leaving both options and variants out keeps the current ones
+	Variants []ProductVariant `json:"variants" validate:"max=100,dive"`
+	UserID   int64
+	// Version comes from the If-Match header
+	Version int
+}
+
+type ProductOption struct {
+	Name   string   `json:"name" validate:"required,max=30"`
+	Values []string `json:"values" validate:"required,min=1,max=20,dive,required,max=30"`
+}
+
+type ProductVariant struct {
+	SKU      string   `json:"sku" validate:"required,max=64"`
+	Options  []string `json:"options" validate:"required,dive,required"`
+	Price    *int     `json:"price" validate:"omitempty,min=0"`
+	Stock    int      `json:"stock" validate:"min=0"`
+	ImageURL string   `json:"imageUrl" validate:"omitempty,url"`
 }
 
 type UpdateProductStock struct {
 	ID    int64
 	Stock int `json:"stock" validate:"required,min=0"`
+	// VariantId is required for products with variants
+	VariantId string `json:"variantId" validate:"omitempty,numeric"`
+	UserID    int64
+	// Version comes from the If-Match header
+	Version int
 }
 
 type GetProducts struct {
@@ -32,19 +67,28 @@ type GetProducts struct {
 	Limit          int      `query:"limit" default:"10"`
 	Offset         int      `query:"offset" default:"0"`
 	Tags           []string `query:"tags"`
+	TagMatch       string   `query:"tagMatch" validate:"omitempty,oneof=all any"`
 	Condition      string   `query:"condition"`
+	Category       int64    `query:"category"`
 	ShowEmptyStock bool     `query:"showEmptyStock"`
 	MaxPrice       float64  `query:"maxPrice"`
 	MinPrice       float64  `query:"minPrice"`
-	SortBy         string   `query:"sortBy"`
-	OrderBy        string   `query:"orderBy"`
-	Search         string   `query:"search"`
+	// SortBy is a comma separated list of sort fields, a leading "-" sorts that field descending
+	SortBy  string `query:"sortBy"`
+	OrderBy string `query:"orderBy"`
+	Search  string `query:"search"`
+	Cursor  string `query:"cursor"`
+	// Facets lists the facets to compute, either repeated or comma separated
+	Facets []string `query:"facets" validate:"dive,oneof=condition tags price stock"`
 }
 
 type PurchaseProduct struct {
 	ProductId            int64  `validate:"required"`
-	BankAccountId        string `json:"bankAccountId" validate:"required"`
+	BankAccountId        string `json:"bankAccountId" validate:"required,numeric"`
 	PaymentProofImageUrl string `json:"paymentProofImageUrl" validate:"required,url"`
 	Quantity             int    `json:"quantity" validate:"required,min=1"`
-	UserID               int64
+	ReservationId        string `json:"reservationId" validate:"omitempty,numeric"`
+	// VariantId is required for products with variants
+	VariantId string `json:"variantId" validate:"omitempty,numeric"`
+	UserID    int64
 }
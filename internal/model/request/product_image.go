@@ -0,0 +1,15 @@
+package request
+
+type AddProductImage struct {
+	ProductId int64  `validate:"required"`
+	URL       string `json:"url" validate:"required,url"`
+	AltText   string `json:"altText" validate:"max=125"`
+	UserID    int64
+}
+
+type ReorderProductImages struct {
+	ProductId int64 `validate:"required"`
+	// ImageIds lists every image of the product in the new order
+	ImageIds []string `json:"imageIds" validate:"required,min=1,dive,numeric"`
+	UserID   int64
+}
@@ -0,0 +1,9 @@
+package request
+
+type ImportProducts struct {
+	UserID int64
+	// DryRun validates the file and reports the result without creating any product
+	DryRun bool `query:"dryRun"`
+	// Report is the format of the result, csv sends it as a file download
+	Report string `query:"report" validate:"omitempty,oneof=json csv"`
+}
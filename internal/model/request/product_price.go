@@ -0,0 +1,8 @@
+package request
+
+type SubscribePriceAlert struct {
+	ProductId int64 `validate:"required"`
+	UserID    int64
+	// TargetPrice must be below the current price, the buyer is notified when the price drops below it
+	TargetPrice int `json:"targetPrice" validate:"required,min=1"`
+}
@@ -0,0 +1,15 @@
+package request
+
+type UpdateProductStocks struct {
+	Items  []UpdateProductStockItem `json:"items" validate:"required,min=1,max=100,dive"`
+	UserID int64
+}
+
+type UpdateProductStockItem struct {
+	ProductId string `json:"productId" validate:"required,numeric"`
+	Stock     *int   `json:"stock" validate:"required,min=0"`
+	// Price is optional, the current price is kept when it is missing
+	Price *int `json:"price" validate:"omitempty,min=1"`
+	// Version is the version the stock-take was made on, the one sent in the product ETag
+	Version int `json:"version" validate:"required,min=1"`
+}
@@ -0,0 +1,8 @@
+package request
+
+type GetProductHistory struct {
+	ProductId int64 `validate:"required"`
+	UserID    int64
+	Limit     int `query:"limit" default:"10"`
+	Offset    int `query:"offset" default:"0"`
+}
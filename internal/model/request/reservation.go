@@ -0,0 +1,7 @@
+package request
+
+type ReserveProduct struct {
+	ProductId int64 `validate:"required"`
+	Quantity  int   `json:"quantity" validate:"required,min=1"`
+	UserID    int64
+}
@@ -0,0 +1,6 @@
+package request
+
+type GetSuggestions struct {
+	Query string `query:"q" validate:"required,max=60"`
+	Limit int    `query:"limit" validate:"min=0,max=20"`
+}
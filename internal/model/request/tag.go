@@ -0,0 +1,6 @@
+package request
+
+type GetTags struct {
+	Query string `query:"q" validate:"max=60"`
+	Limit int    `query:"limit" validate:"min=0,max=100"`
+}
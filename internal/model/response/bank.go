@@ -8,4 +8,6 @@ type Bank struct {
 	UserID        int64  `json:"userId"`
 	CreatedAt     int64  `json:"created_at"`
 	UpdatedAt     int64  `json:"updated_at"`
+	// Version is sent as the ETag header
+	Version int `json:"-"`
 }
@@ -0,0 +1,20 @@
+package response
+
+type Cart struct {
+	Items         []CartItem `json:"items"`
+	TotalQuantity int        `json:"totalQuantity"`
+	TotalPrice    int        `json:"totalPrice"`
+}
+
+type CartItem struct {
+	ProductID string `json:"productId"`
+	Name      string `json:"name"`
+	Price     int    `json:"price"`
+	ImageURL  string `json:"imageUrl"`
+	Stock     int    `json:"stock"`
+	SellerID  int64  `json:"seller_id"`
+	Quantity  int    `json:"quantity"`
+	Subtotal  int    `json:"subtotal"`
+	CreatedAt int64  `json:"created_at"`
+	UpdatedAt int64  `json:"updated_at"`
+}
@@ -0,0 +1,10 @@
+package response
+
+type Category struct {
+	ID        string     `json:"categoryId"`
+	Name      string     `json:"name"`
+	ParentID  string     `json:"parentId,omitempty"`
+	Children  []Category `json:"children"`
+	CreatedAt int64      `json:"created_at"`
+	UpdatedAt int64      `json:"updated_at"`
+}
@@ -0,0 +1,12 @@
+package response
+
+type IdempotencyKey struct {
+	Key          string
+	RequestHash  string
+	ResponseCode int
+	ResponseBody string
+	// ResponseContentType is empty for responses recorded before it was kept, they are JSON
+	ResponseContentType        string
+	ResponseContentDisposition string
+	Completed                  bool
+}
@@ -0,0 +1,10 @@
+package response
+
+type Notification struct {
+	ID        string `json:"notificationId"`
+	Type      string `json:"type"`
+	ProductID string `json:"productId,omitempty"`
+	Message   string `json:"message"`
+	Read      bool   `json:"read"`
+	CreatedAt int64  `json:"createdAt"`
+}
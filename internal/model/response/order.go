@@ -0,0 +1,13 @@
+package response
+
+type Order struct {
+	ID                   string    `json:"orderId"`
+	SellerID             int64     `json:"seller_id"`
+	BankAccountID        string    `json:"bankAccountId"`
+	PaymentProofImageUrl string    `json:"paymentProofImageUrl"`
+	TotalPrice           int       `json:"totalPrice"`
+	Payments             []Payment `json:"payments"`
+	UserID               int64     `json:"user_id"`
+	CreatedAt            int64     `json:"created_at"`
+	UpdatedAt            int64     `json:"updated_at"`
+}
@@ -0,0 +1,30 @@
+package response
+
+type Payment struct {
+	ID                   string          `json:"paymentId"`
+	OrderID              string          `json:"orderId,omitempty"`
+	ProductID            string          `json:"productId"`
+	Product              *PaymentProduct `json:"product,omitempty"`
+	BankAccountID        string          `json:"bankAccountId"`
+	BankAccount          *Bank           `json:"bankAccount,omitempty"`
+	Quantity             int             `json:"quantity"`
+	PaymentProofImageUrl string          `json:"paymentProofImageUrl"`
+	Status               string          `json:"status"`
+	UserID               int64           `json:"user_id"`
+	SellerID             int64           `json:"seller_id"`
+	CreatedAt            int64           `json:"created_at"`
+	UpdatedAt            int64           `json:"updated_at"`
+}
+
+// PaymentProduct is the product as it was when the payment was made
+type PaymentProduct struct {
+	ID       string `json:"productId"`
+	Name     string `json:"name"`
+	Price    int    `json:"price"`
+	ImageURL string `json:"imageUrl"`
+	// Version is the entry of the product history the payment was made on
+	Version int `json:"version,omitempty"`
+	// VariantID and SKU identify the purchased variant, if any
+	VariantID string `json:"variantId,omitempty"`
+	SKU       string `json:"sku,omitempty"`
+}
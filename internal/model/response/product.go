@@ -1,18 +1,45 @@
 package response
 
 type Product struct {
-	ID            string   `json:"productId"`
-	Name          string   `json:"name"`
-	Price         int      `json:"price"`
-	ImageURL      string   `json:"imageUrl"`
-	Stock         int      `json:"stock"`
-	Condition     string   `json:"condition"`
-	Tags          []string `json:"tags"`
-	IsPurchasable bool     `json:"isPurchasable"`
-	PurchaseCount int      `json:"purchaseCount"`
-	UserID        int64    `json:"user_id"`
-	CreatedAt     int64    `json:"created_at"`
-	UpdatedAt     int64    `json:"updated_at"`
+	ID              string           `json:"productId"`
+	Name            string           `json:"name"`
+	Description     string           `json:"description,omitempty"`
+	DescriptionHTML string           `json:"descriptionHtml,omitempty"`
+	Price           int              `json:"price"`
+	ImageURL        string           `json:"imageUrl"`
+	Stock           int              `json:"stock"`
+	Condition       string           `json:"condition"`
+	Tags            []string         `json:"tags"`
+	CategoryID      string           `json:"categoryId,omitempty"`
+	IsPurchasable   bool             `json:"isPurchasable"`
+	PurchaseCount   int              `json:"purchaseCount"`
+	PurchaseLimit   int              `json:"purchaseLimitPerBuyer"`
+	UserID          int64            `json:"user_id"`
+	CreatedAt       int64            `json:"created_at"`
+	UpdatedAt       int64            `json:"updated_at"`
+	Highlight       string           `json:"highlight,omitempty"`
+	Images          []ProductImage   `json:"images"`
+	Options         []ProductOption  `json:"options,omitempty"`
+	Variants        []ProductVariant `json:"variants,omitempty"`
+	// PriceHistory lists the latest price changes, oldest first, only set on the product detail
+	PriceHistory []ProductPrice `json:"priceHistory,omitempty"`
+	// Version is sent as the ETag header of the product detail
+	Version int `json:"-"`
+}
+
+type ProductOption struct {
+	Name   string   `json:"name"`
+	Values []string `json:"values"`
+}
+
+// ProductVariant is one cell of the variant matrix, Price and ImageURL fall back to the product ones
+type ProductVariant struct {
+	ID       string            `json:"variantId"`
+	SKU      string            `json:"sku"`
+	Options  map[string]string `json:"options"`
+	Price    int               `json:"price"`
+	Stock    int               `json:"stock"`
+	ImageURL string            `json:"imageUrl"`
 }
 
 type PurchaseProduct struct {
@@ -20,3 +47,33 @@ type PurchaseProduct struct {
 	PaymentProofImageUrl string `json:"paymentProofImageUrl" validate:"required,url"`
 	Quantity             int    `json:"quantity" validate:"required,min=1"`
 }
+
+// ProductList is a page of products, DidYouMean is only set when a search matched nothing
+type ProductList struct {
+	Products   []Product      `json:"products"`
+	DidYouMean string         `json:"didYouMean,omitempty"`
+	Facets     *ProductFacets `json:"facets,omitempty"`
+}
+
+type ProductFacets struct {
+	Conditions   []FacetCount  `json:"condition,omitempty"`
+	Tags         []FacetCount  `json:"tags,omitempty"`
+	PriceBuckets []PriceBucket `json:"price,omitempty"`
+	Stock        *StockFacet   `json:"stock,omitempty"`
+}
+
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+type PriceBucket struct {
+	Min   int `json:"min"`
+	Max   int `json:"max"`
+	Count int `json:"count"`
+}
+
+type StockFacet struct {
+	InStock    int `json:"inStock"`
+	OutOfStock int `json:"outOfStock"`
+}
@@ -0,0 +1,8 @@
+package response
+
+type ProductImage struct {
+	ID       string `json:"imageId"`
+	URL      string `json:"url"`
+	AltText  string `json:"altText"`
+	Position int    `json:"position"`
+}
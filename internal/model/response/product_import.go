@@ -0,0 +1,20 @@
+package response
+
+// ProductImport is the result of a CSV import, with one entry per data row of the file
+type ProductImport struct {
+	DryRun  bool               `json:"dryRun"`
+	Total   int                `json:"total"`
+	Valid   int                `json:"valid"`
+	Created int                `json:"created"`
+	Failed  int                `json:"failed"`
+	Rows    []ProductImportRow `json:"rows"`
+}
+
+type ProductImportRow struct {
+	// Line is the line of the row in the uploaded file
+	Line      int    `json:"line"`
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	ProductID string `json:"productId,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
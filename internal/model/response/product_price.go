@@ -0,0 +1,12 @@
+package response
+
+type ProductPrice struct {
+	Price     int   `json:"price"`
+	ChangedAt int64 `json:"changedAt"`
+}
+
+type PriceAlert struct {
+	ProductID   string `json:"productId"`
+	TargetPrice int    `json:"targetPrice"`
+	CreatedAt   int64  `json:"createdAt"`
+}
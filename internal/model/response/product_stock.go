@@ -0,0 +1,12 @@
+package response
+
+type ProductStockUpdate struct {
+	ProductID string `json:"productId"`
+	// Status is updated, failed when the item is refused, or skipped when another item of the batch is
+	Status string `json:"status"`
+	Stock  int    `json:"stock"`
+	Price  int    `json:"price"`
+	// Version is the new version of an updated product, to send back in a later update
+	Version int    `json:"version,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
@@ -0,0 +1,12 @@
+package response
+
+import "encoding/json"
+
+type ProductVersion struct {
+	Version   int    `json:"version"`
+	Action    string `json:"action"`
+	UserID    string `json:"userId"`
+	CreatedAt int64  `json:"createdAt"`
+	// Product is the listing right after the change
+	Product json.RawMessage `json:"product"`
+}
@@ -0,0 +1,12 @@
+package response
+
+type Reservation struct {
+	ID        string `json:"reservationId"`
+	ProductID string `json:"productId"`
+	Quantity  int    `json:"quantity"`
+	Status    string `json:"status"`
+	UserID    int64  `json:"user_id"`
+	ExpiresAt int64  `json:"expires_at"`
+	CreatedAt int64  `json:"created_at"`
+	UpdatedAt int64  `json:"updated_at"`
+}
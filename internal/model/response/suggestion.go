@@ -0,0 +1,6 @@
+package response
+
+type Suggestion struct {
+	Term string `json:"term"`
+	Kind string `json:"kind"`
+}
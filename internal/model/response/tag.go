@@ -0,0 +1,6 @@
+package response
+
+type Tag struct {
+	Name  string `json:"tag"`
+	Count int    `json:"count"`
+}
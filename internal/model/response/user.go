@@ -4,6 +4,7 @@ type User struct {
 	ID        int64  `json:"userId"`
 	Username  string `json:"username"`
 	Name      string `json:"name"`
+	IsAdmin   bool   `json:"isAdmin"`
 	CreatedAt int64  `json:"created_at"`
 	UpdatedAt int64  `json:"updated_at"`
 }
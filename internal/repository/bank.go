@@ -14,7 +14,7 @@ import (
 type BankRepository interface {
 	FindAll(ctx context.Context, userId int64) ([]entity.Bank, int, error)
 	FindByID(ctx context.Context, id int64) (*entity.Bank, int, error)
-	DeleteByID(ctx context.Context, id int64) (int, error)
+	DeleteByID(ctx context.Context, id int64, version int) (int, error)
 	UpdateByID(ctx context.Context, ent entity.Bank) (int, error)
 	Create(ctx context.Context, ent entity.Bank) (int, error)
 }
@@ -71,27 +71,35 @@ func (r *BankRepositoryImpl) FindAll(ctx context.Context, userId int64) ([]entit
 	return banks, http.StatusOK, nil
 }
 
-func (r *BankRepositoryImpl) DeleteByID(ctx context.Context, id int64) (int, error) {
-	query := `DELETE FROM banks WHERE id = $1 RETURNING id`
-	rId := 0
-	if err := r.db.QueryRowContext(ctx, query, id).Scan(&rId); err != nil {
+// DeleteByID removes the bank if it is still at the given version
+func (r *BankRepositoryImpl) DeleteByID(ctx context.Context, id int64, version int) (int, error) {
+	query := `DELETE FROM banks WHERE id = $1 AND version = $2`
+	res, err := r.db.ExecContext(ctx, query, id, version)
+	if err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	row, err := res.RowsAffected()
+	if err != nil {
 		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
 	}
-	if rId == 0 {
-		return http.StatusNotFound, errors.Wrap(errorer.ErrNotFound, "product not found")
+
+	if row == 0 {
+		return r.versionMismatch(ctx, id)
 	}
 
 	return http.StatusOK, nil
 }
 
+// UpdateByID updates the bank if it is still at ent.Version and increments its version
 func (r *BankRepositoryImpl) UpdateByID(ctx context.Context, ent entity.Bank) (int, error) {
 	query := `
 		UPDATE banks SET
 			name=$1, 
 			account_name=$2,
 			account_number=$3,
-			updated_at=$4
-		Where id = $5
+			updated_at=$4,
+			version=version + 1
+		Where id = $5 AND version = $6
 	`
 
 	res, err := r.db.ExecContext(ctx, query,
@@ -99,7 +107,8 @@ func (r *BankRepositoryImpl) UpdateByID(ctx context.Context, ent entity.Bank) (i
 		ent.AccountName,
 		ent.AccountNumber,
 		ent.UpdatedAt,
-		ent.ID)
+		ent.ID,
+		ent.Version)
 
 	if err != nil {
 		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
@@ -111,12 +120,24 @@ func (r *BankRepositoryImpl) UpdateByID(ctx context.Context, ent entity.Bank) (i
 	}
 
 	if row == 0 {
-		return http.StatusNotFound, errors.Wrap(errorer.ErrNotFound, errorer.ErrNotFound.Error())
+		return r.versionMismatch(ctx, ent.ID)
 	}
 
 	return http.StatusOK, nil
 }
 
+// versionMismatch tells why a write conditioned on the bank version matched no row
+func (r *BankRepositoryImpl) versionMismatch(ctx context.Context, id int64) (int, error) {
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM banks WHERE id = $1)`, id).Scan(&exists); err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	if !exists {
+		return http.StatusNotFound, errors.Wrap(errorer.ErrNotFound, errorer.ErrNotFound.Error())
+	}
+	return http.StatusPreconditionFailed, errors.Wrap(errorer.ErrPreconditionFailed, "bank has been modified since it was read")
+}
+
 func (r *BankRepositoryImpl) Create(ctx context.Context, ent entity.Bank) (int, error) {
 	// insert bank
 	query := `
@@ -152,7 +173,8 @@ func (r *BankRepositoryImpl) FindByID(ctx context.Context, id int64) (*entity.Ba
 			account_number,
 			user_id,
 			created_at,
-			updated_at
+			updated_at,
+			version
 		FROM banks
 		WHERE banks.id = $1
 	`
@@ -164,6 +186,7 @@ func (r *BankRepositoryImpl) FindByID(ctx context.Context, id int64) (*entity.Ba
 		&bank.UserID,
 		&bank.CreatedAt,
 		&bank.UpdatedAt,
+		&bank.Version,
 	)
 
 	if err != nil {
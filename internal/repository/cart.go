@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"ecomm/internal/helper/errorer"
+	"ecomm/internal/model/entity"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+type CartRepository interface {
+	FindAll(ctx context.Context, userId int64) ([]entity.CartItem, int, error)
+	Add(ctx context.Context, ent entity.CartItem) (int, error)
+	UpdateQuantity(ctx context.Context, ent entity.CartItem) (int, error)
+	Delete(ctx context.Context, userId int64, productId int64) (int, error)
+}
+
+func NewCartRepository(logger zerolog.Logger, db *sql.DB) CartRepository {
+	return &CartRepositoryImpl{
+		logger: logger,
+		db:     db,
+	}
+}
+
+type CartRepositoryImpl struct {
+	logger zerolog.Logger
+	db     *sql.DB
+}
+
+func (r *CartRepositoryImpl) FindAll(ctx context.Context, userId int64) ([]entity.CartItem, int, error) {
+	items := []entity.CartItem{}
+	query := `
+		SELECT
+			c.id,
+			c.user_id,
+			c.product_id,
+			c.quantity,
+			c.created_at,
+			c.updated_at,
+			p.name,
+			p.price,
+			p.image_url,
+			p.stock - ` + reservedStockQuery("p.id") + `,
+			p.is_purchasable,
+			p.purchase_limit_per_buyer,
+			p.user_id,
+			` + productHasVariantsQuery("p.id") + `
+		FROM cart_items as c
+		JOIN products as p ON c.product_id = p.id AND p.deleted_at IS NULL
+		WHERE c.user_id = $1
+		ORDER BY c.created_at, c.id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userId)
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item := entity.CartItem{}
+		if err := rows.Scan(
+			&item.ID,
+			&item.UserID,
+			&item.ProductID,
+			&item.Quantity,
+			&item.CreatedAt,
+			&item.UpdatedAt,
+			&item.Product.Name,
+			&item.Product.Price,
+			&item.Product.ImageURL,
+			&item.Product.Stock,
+			&item.Product.IsPurchasable,
+			&item.Product.PurchaseLimit,
+			&item.Product.UserID,
+			&item.Product.HasVariants,
+		); err != nil {
+			return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+		}
+		item.Product.ID = item.ProductID
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	return items, http.StatusOK, nil
+}
+
+// Add puts the product in the cart, or increases its quantity when it is already there
+func (r *CartRepositoryImpl) Add(ctx context.Context, ent entity.CartItem) (int, error) {
+	query := `
+		Insert into cart_items
+		(
+			user_id,
+			product_id,
+			quantity,
+			created_at,
+			updated_at
+		)
+		Values($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id, product_id) DO UPDATE SET
+			quantity = cart_items.quantity + EXCLUDED.quantity,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	_, err := r.db.ExecContext(ctx, query, ent.UserID, ent.ProductID, ent.Quantity, ent.CreatedAt, ent.UpdatedAt)
+	if err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	return http.StatusOK, nil
+}
+
+func (r *CartRepositoryImpl) UpdateQuantity(ctx context.Context, ent entity.CartItem) (int, error) {
+	query := `
+		UPDATE cart_items SET
+			quantity=$1,
+			updated_at=$2
+		WHERE user_id = $3 AND product_id = $4
+	`
+
+	res, err := r.db.ExecContext(ctx, query, ent.Quantity, ent.UpdatedAt, ent.UserID, ent.ProductID)
+	if err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	row, err := res.RowsAffected()
+	if err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	if row == 0 {
+		return http.StatusNotFound, errors.Wrap(errorer.ErrNotFound, errorer.ErrNotFound.Error())
+	}
+
+	return http.StatusOK, nil
+}
+
+func (r *CartRepositoryImpl) Delete(ctx context.Context, userId int64, productId int64) (int, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM cart_items WHERE user_id = $1 AND product_id = $2`, userId, productId)
+	if err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	row, err := res.RowsAffected()
+	if err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	if row == 0 {
+		return http.StatusNotFound, errors.Wrap(errorer.ErrNotFound, errorer.ErrNotFound.Error())
+	}
+
+	return http.StatusOK, nil
+}
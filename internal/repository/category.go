@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"ecomm/internal/helper/errorer"
+	"ecomm/internal/model/entity"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+// categoryDescendantsQuery selects the id of the category bound to the given placeholder and of all its descendants
+func categoryDescendantsQuery(placeholder string) string {
+	return `WITH RECURSIVE descendants AS (
+			SELECT id FROM categories WHERE id = ` + placeholder + `
+			UNION ALL
+			SELECT c.id FROM categories as c JOIN descendants as d ON c.parent_id = d.id
+		) SELECT id FROM descendants`
+}
+
+type CategoryRepository interface {
+	FindAll(ctx context.Context) ([]entity.Category, int, error)
+	FindByID(ctx context.Context, id int64) (*entity.Category, int, error)
+	IsDescendant(ctx context.Context, id int64, ancestorId int64) (bool, int, error)
+	Create(ctx context.Context, ent entity.Category) (*entity.Category, int, error)
+	UpdateByID(ctx context.Context, ent entity.Category) (int, error)
+	DeleteByID(ctx context.Context, id int64) (int, error)
+}
+
+func NewCategoryRepository(logger zerolog.Logger, db *sql.DB) CategoryRepository {
+	return &CategoryRepositoryImpl{
+		logger: logger,
+		db:     db,
+	}
+}
+
+type CategoryRepositoryImpl struct {
+	logger zerolog.Logger
+	db     *sql.DB
+}
+
+func nullableID(id int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: id, Valid: id != 0}
+}
+
+func (r *CategoryRepositoryImpl) FindAll(ctx context.Context) ([]entity.Category, int, error) {
+	categories := []entity.Category{}
+	rows, err := r.db.QueryContext(ctx, `SELECT id, name, parent_id, created_at, updated_at FROM categories ORDER BY name, id`)
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		category := entity.Category{}
+		var parentId sql.NullInt64
+		if err := rows.Scan(&category.ID, &category.Name, &parentId, &category.CreatedAt, &category.UpdatedAt); err != nil {
+			return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+		}
+		category.ParentID = parentId.Int64
+		categories = append(categories, category)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	return categories, http.StatusOK, nil
+}
+
+func (r *CategoryRepositoryImpl) FindByID(ctx context.Context, id int64) (*entity.Category, int, error) {
+	category := entity.Category{}
+	var parentId sql.NullInt64
+	err := r.db.QueryRowContext(ctx, `SELECT id, name, parent_id, created_at, updated_at FROM categories WHERE id = $1`, id).
+		Scan(&category.ID, &category.Name, &parentId, &category.CreatedAt, &category.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, http.StatusNotFound, errors.Wrap(errorer.ErrNotFound, errorer.ErrNotFound.Error())
+		}
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	category.ParentID = parentId.Int64
+
+	return &category, http.StatusOK, nil
+}
+
+// IsDescendant reports whether the category is the ancestor itself or one of its descendants
+func (r *CategoryRepositoryImpl) IsDescendant(ctx context.Context, id int64, ancestorId int64) (bool, int, error) {
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM (` + categoryDescendantsQuery("$1") + `) as d WHERE d.id = $2)`
+	if err := r.db.QueryRowContext(ctx, query, ancestorId, id).Scan(&exists); err != nil {
+		return false, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	return exists, http.StatusOK, nil
+}
+
+func (r *CategoryRepositoryImpl) Create(ctx context.Context, ent entity.Category) (*entity.Category, int, error) {
+	query := `
+		Insert into categories
+		(
+			name,
+			parent_id,
+			created_at,
+			updated_at
+		)
+		Values($1, $2, $3, $4)
+		RETURNING id;
+	`
+
+	err := r.db.QueryRowContext(ctx, query, ent.Name, nullableID(ent.ParentID), ent.CreatedAt, ent.UpdatedAt).Scan(&ent.ID)
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	return &ent, http.StatusOK, nil
+}
+
+func (r *CategoryRepositoryImpl) UpdateByID(ctx context.Context, ent entity.Category) (int, error) {
+	query := `
+		UPDATE categories SET
+			name=$1,
+			parent_id=$2,
+			updated_at=$3
+		Where id = $4
+	`
+
+	res, err := r.db.ExecContext(ctx, query, ent.Name, nullableID(ent.ParentID), ent.UpdatedAt, ent.ID)
+	if err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	row, err := res.RowsAffected()
+	if err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	if row == 0 {
+		return http.StatusNotFound, errors.Wrap(errorer.ErrNotFound, errorer.ErrNotFound.Error())
+	}
+
+	return http.StatusOK, nil
+}
+
+// DeleteByID deletes a category that has neither subcategories nor products
+func (r *CategoryRepositoryImpl) DeleteByID(ctx context.Context, id int64) (int, error) {
+	var inUse bool
+	query := `SELECT EXISTS (SELECT 1 FROM categories WHERE parent_id = $1) OR EXISTS (SELECT 1 FROM products WHERE category_id = $1)`
+	if err := r.db.QueryRowContext(ctx, query, id).Scan(&inUse); err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	if inUse {
+		return http.StatusConflict, errors.Wrap(errorer.ErrConflict, "category still has subcategories or products")
+	}
+
+	res, err := r.db.ExecContext(ctx, `DELETE FROM categories WHERE id = $1`, id)
+	if err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	row, err := res.RowsAffected()
+	if err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	if row == 0 {
+		return http.StatusNotFound, errors.Wrap(errorer.ErrNotFound, errorer.ErrNotFound.Error())
+	}
+
+	return http.StatusOK, nil
+}
@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"ecomm/internal/helper/errorer"
+	"ecomm/internal/model/entity"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+type IdempotencyRepository interface {
+	Acquire(ctx context.Context, ent entity.IdempotencyKey) (*entity.IdempotencyKey, int, error)
+	SaveResponse(ctx context.Context, ent entity.IdempotencyKey) (int, error)
+	Delete(ctx context.Context, userId int64, key string) (int, error)
+	DeleteExpired(ctx context.Context, now int64) (int64, int, error)
+}
+
+func NewIdempotencyRepository(logger zerolog.Logger, db *sql.DB) IdempotencyRepository {
+	return &IdempotencyRepositoryImpl{
+		logger: logger,
+		db:     db,
+	}
+}
+
+type IdempotencyRepositoryImpl struct {
+	logger zerolog.Logger
+	db     *sql.DB
+}
+
+// Acquire claims the key for a new request. When the key is already in use it returns the existing
+// record instead, and nil when the caller now owns the key
+func (r *IdempotencyRepositoryImpl) Acquire(ctx context.Context, ent entity.IdempotencyKey) (*entity.IdempotencyKey, int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	defer tx.Rollback()
+
+	// an expired key can be reused as if it was never seen
+	_, err = tx.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE user_id = $1 AND key = $2 AND expires_at <= $3`,
+		ent.UserID, ent.Key, ent.CreatedAt)
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	query := `
+		Insert into idempotency_keys
+		(
+			user_id,
+			key,
+			request_hash,
+			expires_at,
+			created_at,
+			updated_at
+		)
+		Values($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id, key) DO NOTHING
+	`
+	res, err := tx.ExecContext(ctx, query, ent.UserID, ent.Key, ent.RequestHash, ent.ExpiresAt, ent.CreatedAt, ent.UpdatedAt)
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	row, err := res.RowsAffected()
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	var existing *entity.IdempotencyKey
+	if row == 0 {
+		existing = &entity.IdempotencyKey{}
+		var responseCode sql.NullInt64
+		var responseBody, responseContentType, responseContentDisposition sql.NullString
+		err = tx.QueryRowContext(ctx, `
+			SELECT id, user_id, key, request_hash, response_code, response_body, response_content_type, response_content_disposition, expires_at, created_at, updated_at
+			FROM idempotency_keys
+			WHERE user_id = $1 AND key = $2
+		`, ent.UserID, ent.Key).Scan(
+			&existing.ID,
+			&existing.UserID,
+			&existing.Key,
+			&existing.RequestHash,
+			&responseCode,
+			&responseBody,
+			&responseContentType,
+			&responseContentDisposition,
+			&existing.ExpiresAt,
+			&existing.CreatedAt,
+			&existing.UpdatedAt,
+		)
+		if err != nil {
+			return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+		}
+		existing.ResponseCode = int(responseCode.Int64)
+		existing.ResponseBody = responseBody.String
+		existing.ResponseContentType = responseContentType.String
+		existing.ResponseContentDisposition = responseContentDisposition.String
+		existing.Completed = responseCode.Valid
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	return existing, http.StatusOK, nil
+}
+
+func (r *IdempotencyRepositoryImpl) SaveResponse(ctx context.Context, ent entity.IdempotencyKey) (int, error) {
+	query := `
+		UPDATE idempotency_keys SET
+			response_code=$1,
+			response_body=$2,
+			updated_at=$3,
+			response_content_type=$6,
+			response_content_disposition=$7
+		WHERE user_id = $4 AND key = $5
+	`
+
+	res, err := r.db.ExecContext(ctx, query, ent.ResponseCode, ent.ResponseBody, ent.UpdatedAt, ent.UserID, ent.Key,
+		ent.ResponseContentType, ent.ResponseContentDisposition)
+	if err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	row, err := res.RowsAffected()
+	if err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	if row == 0 {
+		return http.StatusNotFound, errors.Wrap(errorer.ErrNotFound, errorer.ErrNotFound.Error())
+	}
+
+	return http.StatusOK, nil
+}
+
+func (r *IdempotencyRepositoryImpl) Delete(ctx context.Context, userId int64, key string) (int, error) {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE user_id = $1 AND key = $2`, userId, key)
+	if err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	return http.StatusOK, nil
+}
+
+func (r *IdempotencyRepositoryImpl) DeleteExpired(ctx context.Context, now int64) (int64, int, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= $1`, now)
+	if err != nil {
+		return 0, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	row, err := res.RowsAffected()
+	if err != nil {
+		return 0, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	return row, http.StatusOK, nil
+}
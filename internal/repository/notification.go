@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"ecomm/internal/helper/common"
+	"ecomm/internal/helper/errorer"
+	"ecomm/internal/model/entity"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+type NotificationRepository interface {
+	FindAll(ctx context.Context, filter entity.GetAllNotificationFilter) ([]entity.Notification, *common.Meta, int, error)
+	MarkRead(ctx context.Context, id int64, userId int64, readAt int64) (int, error)
+}
+
+func NewNotificationRepository(logger zerolog.Logger, db *sql.DB) NotificationRepository {
+	return &NotificationRepositoryImpl{
+		logger: logger,
+		db:     db,
+	}
+}
+
+type NotificationRepositoryImpl struct {
+	logger zerolog.Logger
+	db     *sql.DB
+}
+
+// FindAll returns the notifications of the user, latest first
+func (r *NotificationRepositoryImpl) FindAll(ctx context.Context, filter entity.GetAllNotificationFilter) ([]entity.Notification, *common.Meta, int, error) {
+	whereClause := "WHERE user_id = $1"
+	if filter.UnreadOnly {
+		whereClause += " AND read_at IS NULL"
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, user_id, type, product_id, message, read_at, created_at
+		FROM notifications
+		`+whereClause+`
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2 OFFSET $3
+	`, filter.UserID, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	defer rows.Close()
+
+	notifications := []entity.Notification{}
+	for rows.Next() {
+		notification := entity.Notification{}
+		var productId, readAt sql.NullInt64
+		if err := rows.Scan(
+			&notification.ID,
+			&notification.UserID,
+			&notification.Type,
+			&productId,
+			&notification.Message,
+			&readAt,
+			&notification.CreatedAt,
+		); err != nil {
+			return nil, nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+		}
+		notification.ProductID = productId.Int64
+		notification.ReadAt = readAt.Int64
+		notifications = append(notifications, notification)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	var totalCount int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM notifications "+whereClause, filter.UserID).Scan(&totalCount); err != nil {
+		return nil, nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	return notifications, &common.Meta{
+		Total:  totalCount,
+		Limit:  filter.Limit,
+		Offset: filter.Offset,
+	}, http.StatusOK, nil
+}
+
+// MarkRead marks a notification of the user as read, reading it again keeps the first read time
+func (r *NotificationRepositoryImpl) MarkRead(ctx context.Context, id int64, userId int64, readAt int64) (int, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE notifications SET read_at = COALESCE(read_at, $3)
+		WHERE id = $1 AND user_id = $2
+	`, id, userId, readAt)
+	if err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	row, err := res.RowsAffected()
+	if err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	if row == 0 {
+		return http.StatusNotFound, errors.Wrap(errorer.ErrNotFound, "notification not found")
+	}
+
+	return http.StatusOK, nil
+}
@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"ecomm/internal/helper/errorer"
+	"ecomm/internal/model/entity"
+	"net/http"
+	"sort"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+type OrderRepository interface {
+	Checkout(ctx context.Context, userId int64, orders []entity.Order) ([]entity.Order, int, error)
+}
+
+func NewOrderRepository(logger zerolog.Logger, db *sql.DB) OrderRepository {
+	return &OrderRepositoryImpl{
+		logger: logger,
+		db:     db,
+	}
+}
+
+type OrderRepositoryImpl struct {
+	logger zerolog.Logger
+	db     *sql.DB
+}
+
+// Checkout decrements the stock of every cart item, records one order per seller with a payment per item
+// and empties the checked out items from the cart, all in a single transaction
+func (r *OrderRepositoryImpl) Checkout(ctx context.Context, userId int64, orders []entity.Order) ([]entity.Order, int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	defer tx.Rollback()
+
+	quantities := map[int64]int{}
+	for _, order := range orders {
+		for _, item := range order.Items {
+			quantities[item.ProductID] += item.Quantity
+		}
+	}
+
+	// The cart was read before the transaction, lock it and make sure the quantities being charged are still
+	// the ones in the cart so the items removed below are exactly the checked out ones
+	rows, err := tx.QueryContext(ctx, `SELECT product_id, quantity FROM cart_items WHERE user_id = $1 FOR UPDATE`, userId)
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	defer rows.Close()
+
+	cart := map[int64]int{}
+	for rows.Next() {
+		var productId int64
+		var quantity int
+		if err := rows.Scan(&productId, &quantity); err != nil {
+			return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+		}
+		cart[productId] = quantity
+	}
+	if err := rows.Err(); err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	rows.Close()
+
+	for id, quantity := range quantities {
+		if cart[id] != quantity {
+			return nil, http.StatusConflict, errors.Wrap(errorer.ErrConflict, "cart has changed during checkout")
+		}
+	}
+
+	// Lock the products in ascending id order so concurrent checkouts cannot deadlock
+	productIds := make([]int64, 0, len(quantities))
+	for id := range quantities {
+		productIds = append(productIds, id)
+	}
+	sort.Slice(productIds, func(i, j int) bool { return productIds[i] < productIds[j] })
+
+	products := map[int64]*entity.Product{}
+	for _, id := range productIds {
+		prd, code, err := purchaseProductTx(ctx, tx, id, 0, quantities[id], userId)
+		if err != nil {
+			return nil, code, err
+		}
+		products[id] = prd
+	}
+
+	for i := range orders {
+		order := &orders[i]
+		order.TotalPrice = 0
+		for _, item := range order.Items {
+			prd := products[item.ProductID]
+			if prd.UserID != order.SellerID {
+				return nil, http.StatusConflict, errors.Wrap(errorer.ErrConflict, "product seller has changed")
+			}
+			order.TotalPrice += prd.Price * item.Quantity
+		}
+
+		query := `
+			Insert into orders
+			(
+				user_id,
+				seller_id,
+				bank_id,
+				payment_proof_image_url,
+				total_price,
+				created_at,
+				updated_at
+			)
+			Values($1, $2, $3, $4, $5, $6, $7)
+			RETURNING id;
+		`
+		err := tx.QueryRowContext(ctx, query, userId, order.SellerID, order.BankID, order.PaymentProofImageURL,
+			order.TotalPrice, order.CreatedAt, order.UpdatedAt).Scan(&order.ID)
+		if err != nil {
+			return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+		}
+		order.UserID = userId
+
+		order.Payments = make([]entity.Payment, len(order.Items))
+		for j, item := range order.Items {
+			prd := products[item.ProductID]
+			payment := entity.Payment{
+				OrderID:              order.ID,
+				UserID:               userId,
+				SellerID:             order.SellerID,
+				ProductID:            item.ProductID,
+				BankID:               order.BankID,
+				Quantity:             item.Quantity,
+				PaymentProofImageURL: order.PaymentProofImageURL,
+				ProductName:          prd.Name,
+				ProductPrice:         prd.Price,
+				ProductImageURL:      prd.ImageURL,
+				ProductVersion:       prd.Version,
+				CreatedAt:            order.CreatedAt,
+				UpdatedAt:            order.UpdatedAt,
+			}
+			if err := insertPaymentTx(ctx, tx, &payment); err != nil {
+				return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+			}
+			order.Payments[j] = payment
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM cart_items WHERE user_id = $1 AND product_id = ANY($2)`, userId, pq.Array(productIds))
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	return orders, http.StatusOK, nil
+}
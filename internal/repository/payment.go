@@ -0,0 +1,420 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"ecomm/internal/helper/common"
+	"ecomm/internal/helper/errorer"
+	"ecomm/internal/model/entity"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+type PaymentRepository interface {
+	FindAll(ctx context.Context, filter entity.GetAllPaymentFilter) ([]entity.Payment, *common.Meta, int, error)
+	FindByID(ctx context.Context, id int64) (*entity.Payment, int, error)
+	Create(ctx context.Context, ent entity.Payment) (*entity.Payment, int, error)
+	UpdateStatus(ctx context.Context, id int64, from string, to string, updatedAt int64) (int, error)
+	UpdateStatusAndRestock(ctx context.Context, id int64, from string, to string, updatedAt int64) (int, error)
+	SumQuantityByBuyer(ctx context.Context, userId int64, productId int64) (int, int, error)
+	StreamBySellerID(ctx context.Context, sellerId int64, fn func(entity.Payment) error) (int, error)
+}
+
+func NewPaymentRepository(logger zerolog.Logger, db *sql.DB) PaymentRepository {
+	return &PaymentRepositoryImpl{
+		logger: logger,
+		db:     db,
+	}
+}
+
+type PaymentRepositoryImpl struct {
+	logger zerolog.Logger
+	db     *sql.DB
+}
+
+// paymentColumns is the column list read by scanPayment
+const paymentColumns = `
+			p.id,
+			p.order_id,
+			p.user_id,
+			p.seller_id,
+			p.product_id,
+			p.bank_id,
+			p.quantity,
+			p.payment_proof_image_url,
+			p.status,
+			p.product_name,
+			p.product_price,
+			p.product_image_url,
+			p.product_version,
+			p.variant_id,
+			p.variant_sku,
+			p.created_at,
+			p.updated_at,
+			b.id,
+			b.name,
+			b.account_name,
+			b.account_number,
+			b.user_id`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanPayment(row rowScanner, payment *entity.Payment) error {
+	var orderId, productVersion, variantId sql.NullInt64
+	var variantSku sql.NullString
+	err := row.Scan(
+		&payment.ID,
+		&orderId,
+		&payment.UserID,
+		&payment.SellerID,
+		&payment.ProductID,
+		&payment.BankID,
+		&payment.Quantity,
+		&payment.PaymentProofImageURL,
+		&payment.Status,
+		&payment.ProductName,
+		&payment.ProductPrice,
+		&payment.ProductImageURL,
+		&productVersion,
+		&variantId,
+		&variantSku,
+		&payment.CreatedAt,
+		&payment.UpdatedAt,
+		&payment.Bank.ID,
+		&payment.Bank.Name,
+		&payment.Bank.AccountName,
+		&payment.Bank.AccountNumber,
+		&payment.Bank.UserID,
+	)
+	payment.OrderID = orderId.Int64
+	payment.ProductVersion = int(productVersion.Int64)
+	payment.VariantID = variantId.Int64
+	payment.VariantSKU = variantSku.String
+	return err
+}
+
+func (r *PaymentRepositoryImpl) FindAll(ctx context.Context, filter entity.GetAllPaymentFilter) ([]entity.Payment, *common.Meta, int, error) {
+	var conditions []string
+	var args []interface{}
+	argIndex := 1 // Start index for placeholder arguments
+
+	if filter.UserID != 0 {
+		conditions = append(conditions, "p.user_id = $"+fmt.Sprint(argIndex))
+		args = append(args, filter.UserID)
+		argIndex++
+	}
+
+	if filter.SellerID != 0 {
+		conditions = append(conditions, "p.seller_id = $"+fmt.Sprint(argIndex))
+		args = append(args, filter.SellerID)
+		argIndex++
+	}
+
+	if filter.ProductID != 0 {
+		conditions = append(conditions, "p.product_id = $"+fmt.Sprint(argIndex))
+		args = append(args, filter.ProductID)
+		argIndex++
+	}
+
+	if filter.Status != "" {
+		conditions = append(conditions, "p.status = $"+fmt.Sprint(argIndex))
+		args = append(args, filter.Status)
+		argIndex++
+	}
+
+	if filter.StartDate > 0 {
+		conditions = append(conditions, "p.created_at >= $"+fmt.Sprint(argIndex))
+		args = append(args, filter.StartDate)
+		argIndex++
+	}
+
+	if filter.EndDate > 0 {
+		conditions = append(conditions, "p.created_at <= $"+fmt.Sprint(argIndex))
+		args = append(args, filter.EndDate)
+		argIndex++
+	}
+
+	// Construct the WHERE clause
+	var whereClause string
+	if len(conditions) > 0 {
+		whereClause = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	limitOffsetClause := fmt.Sprintf("LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
+
+	query := `SELECT ` + paymentColumns + `
+		FROM payments as p
+		JOIN banks as b ON p.bank_id = b.id
+		` + whereClause + ` ORDER BY p.created_at DESC, p.id DESC ` + limitOffsetClause
+
+	countQuery := "SELECT COUNT(*) FROM payments as p " + whereClause
+
+	argsQuery := []interface{}{}
+	argsQuery = append(argsQuery, args...)
+	argsQuery = append(argsQuery, filter.Limit, filter.Offset)
+
+	rows, err := r.db.QueryContext(ctx, query, argsQuery...)
+	if err != nil {
+		return nil, nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	defer rows.Close()
+
+	payments := []entity.Payment{}
+	for rows.Next() {
+		payment := entity.Payment{}
+		if err := scanPayment(rows, &payment); err != nil {
+			return nil, nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+		}
+		payments = append(payments, payment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	var totalCount int
+	if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&totalCount); err != nil {
+		return nil, nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	return payments, &common.Meta{
+		Total:  totalCount,
+		Limit:  filter.Limit,
+		Offset: filter.Offset,
+	}, http.StatusOK, nil
+}
+
+// StreamBySellerID calls fn with every payment received by the seller in creation order, reading them
+// one at a time from the database cursor. A fn error stops the iteration and is returned as is
+func (r *PaymentRepositoryImpl) StreamBySellerID(ctx context.Context, sellerId int64, fn func(entity.Payment) error) (int, error) {
+	query := `SELECT ` + paymentColumns + `
+		FROM payments as p
+		JOIN banks as b ON p.bank_id = b.id
+		WHERE p.seller_id = $1
+		ORDER BY p.created_at, p.id`
+
+	rows, err := r.db.QueryContext(ctx, query, sellerId)
+	if err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		payment := entity.Payment{}
+		if err := scanPayment(rows, &payment); err != nil {
+			return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+		}
+		if err := fn(payment); err != nil {
+			return http.StatusInternalServerError, err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	return http.StatusOK, nil
+}
+
+func (r *PaymentRepositoryImpl) FindByID(ctx context.Context, id int64) (*entity.Payment, int, error) {
+	payment := entity.Payment{}
+	query := `SELECT ` + paymentColumns + `
+		FROM payments as p
+		JOIN banks as b ON p.bank_id = b.id
+		WHERE p.id = $1
+	`
+	if err := scanPayment(r.db.QueryRowContext(ctx, query, id), &payment); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, http.StatusNotFound, errors.Wrap(errorer.ErrNotFound, errorer.ErrNotFound.Error())
+		}
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	return &payment, http.StatusOK, nil
+}
+
+// Create decrements the product stock and records the payment in a single transaction
+func (r *PaymentRepositoryImpl) Create(ctx context.Context, ent entity.Payment) (*entity.Payment, int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	defer tx.Rollback()
+
+	if ent.ReservationID != 0 {
+		code, err := consumeReservationTx(ctx, tx, ent.ReservationID, ent.UserID, ent.ProductID, ent.Quantity, ent.UpdatedAt)
+		if err != nil {
+			return nil, code, err
+		}
+	}
+
+	prd, code, err := purchaseProductTx(ctx, tx, ent.ProductID, ent.VariantID, ent.Quantity, ent.UserID)
+	if err != nil {
+		return nil, code, err
+	}
+	if len(prd.Variants) > 0 {
+		ent.VariantSKU = prd.Variants[0].SKU
+	}
+
+	ent.SellerID = prd.UserID
+	ent.ProductName = prd.Name
+	ent.ProductPrice = prd.Price
+	ent.ProductImageURL = prd.ImageURL
+	ent.ProductVersion = prd.Version
+	if err := insertPaymentTx(ctx, tx, &ent); err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	return &ent, http.StatusOK, nil
+}
+
+// UpdateStatus moves the payment from one status to another, failing with a conflict
+// when the payment is no longer in the expected status
+func (r *PaymentRepositoryImpl) UpdateStatus(ctx context.Context, id int64, from string, to string, updatedAt int64) (int, error) {
+	query := `
+		UPDATE payments SET
+			status=$1,
+			updated_at=$2
+		WHERE id = $3 AND status = $4
+	`
+
+	res, err := r.db.ExecContext(ctx, query, to, updatedAt, id, from)
+	if err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	row, err := res.RowsAffected()
+	if err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	if row == 0 {
+		return http.StatusConflict, errors.Wrap(errorer.ErrConflict, "payment status has changed")
+	}
+
+	return http.StatusOK, nil
+}
+
+// UpdateStatusAndRestock moves the payment from one status to another and returns the purchased
+// quantity to the product stock in the same transaction
+func (r *PaymentRepositoryImpl) UpdateStatusAndRestock(ctx context.Context, id int64, from string, to string, updatedAt int64) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE payments SET
+			status=$1,
+			updated_at=$2
+		WHERE id = $3 AND status = $4
+		RETURNING product_id, quantity, variant_id
+	`
+
+	var productId int64
+	var quantity int
+	var variantId sql.NullInt64
+	err = tx.QueryRowContext(ctx, query, to, updatedAt, id, from).Scan(&productId, &quantity, &variantId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return http.StatusConflict, errors.Wrap(errorer.ErrConflict, "payment status has changed")
+		}
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE products SET
+			stock = stock + $1,
+			purchase_count = GREATEST(purchase_count - $1, 0)
+		WHERE id = $2
+	`, quantity, productId)
+	if err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	if variantId.Valid {
+		_, err = tx.ExecContext(ctx, `UPDATE product_variants SET stock = stock + $1 WHERE id = $2`, quantity, variantId.Int64)
+		if err != nil {
+			return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	return http.StatusOK, nil
+}
+
+// insertPaymentTx records a pending payment inside the given transaction and sets its ID
+func insertPaymentTx(ctx context.Context, tx *sql.Tx, ent *entity.Payment) error {
+	var orderId sql.NullInt64
+	if ent.OrderID != 0 {
+		orderId = sql.NullInt64{Int64: ent.OrderID, Valid: true}
+	}
+	ent.Status = entity.PaymentStatusPendingVerification
+
+	query := `
+		Insert into payments
+		(
+			order_id,
+			user_id,
+			seller_id,
+			product_id,
+			bank_id,
+			quantity,
+			payment_proof_image_url,
+			status,
+			product_name,
+			product_price,
+			product_image_url,
+			product_version,
+			variant_id,
+			variant_sku,
+			created_at,
+			updated_at
+		)
+		Values($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+		RETURNING id;
+	`
+	return tx.QueryRowContext(ctx, query, orderId, ent.UserID, ent.SellerID, ent.ProductID, ent.BankID, ent.Quantity,
+		ent.PaymentProofImageURL, ent.Status, ent.ProductName, ent.ProductPrice, ent.ProductImageURL,
+		ent.ProductVersion, nullableID(ent.VariantID), sql.NullString{String: ent.VariantSKU, Valid: ent.VariantSKU != ""},
+		ent.CreatedAt, ent.UpdatedAt).Scan(&ent.ID)
+}
+
+// SumQuantityByBuyer returns how many units of the product the buyer has purchased, ignoring rejected and cancelled payments
+func (r *PaymentRepositoryImpl) SumQuantityByBuyer(ctx context.Context, userId int64, productId int64) (int, int, error) {
+	total, err := sumQuantityByBuyer(ctx, r.db, userId, productId)
+	if err != nil {
+		return 0, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	return total, http.StatusOK, nil
+}
+
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// sumQuantityByBuyer is SumQuantityByBuyer on either the database or a transaction
+func sumQuantityByBuyer(ctx context.Context, db rowQuerier, userId int64, productId int64) (int, error) {
+	var total int
+	query := `
+		SELECT COALESCE(SUM(quantity), 0)
+		FROM payments
+		WHERE user_id = $1 AND product_id = $2 AND status NOT IN ($3, $4)
+	`
+	err := db.QueryRowContext(ctx, query, userId, productId,
+		entity.PaymentStatusRejected, entity.PaymentStatusCancelled).Scan(&total)
+	return total, err
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"ecomm/internal/helper/errorer"
+	"ecomm/internal/model/entity"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+type PriceAlertRepository interface {
+	Upsert(ctx context.Context, ent entity.PriceAlert) (*entity.PriceAlert, int, error)
+	Delete(ctx context.Context, productId int64, userId int64) (int, error)
+}
+
+func NewPriceAlertRepository(logger zerolog.Logger, db *sql.DB) PriceAlertRepository {
+	return &PriceAlertRepositoryImpl{
+		logger: logger,
+		db:     db,
+	}
+}
+
+type PriceAlertRepositoryImpl struct {
+	logger zerolog.Logger
+	db     *sql.DB
+}
+
+// Upsert subscribes the buyer to the product, replacing the target price of an existing alert
+func (r *PriceAlertRepositoryImpl) Upsert(ctx context.Context, ent entity.PriceAlert) (*entity.PriceAlert, int, error) {
+	query := `
+		INSERT INTO price_alerts (product_id, user_id, target_price, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (product_id, user_id) DO UPDATE SET
+			target_price = EXCLUDED.target_price,
+			created_at = EXCLUDED.created_at
+		RETURNING id
+	`
+	err := r.db.QueryRowContext(ctx, query, ent.ProductID, ent.UserID, ent.TargetPrice, ent.CreatedAt).Scan(&ent.ID)
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	return &ent, http.StatusOK, nil
+}
+
+func (r *PriceAlertRepositoryImpl) Delete(ctx context.Context, productId int64, userId int64) (int, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM price_alerts WHERE product_id = $1 AND user_id = $2`, productId, userId)
+	if err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	row, err := res.RowsAffected()
+	if err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	if row == 0 {
+		return http.StatusNotFound, errors.Wrap(errorer.ErrNotFound, "price alert not found")
+	}
+
+	return http.StatusOK, nil
+}
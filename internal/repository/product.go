@@ -6,24 +6,42 @@ import (
 	"ecomm/internal/helper/common"
 	"ecomm/internal/helper/errorer"
 	"ecomm/internal/model/entity"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"strings"
 	"time"
 
+	"github.com/lib/pq"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
 )
 
+// productTagsQuery selects the tags of the product referenced by the given SQL expression as an array
+func productTagsQuery(productIdExpr string) string {
+	return `ARRAY(SELECT pt.tag FROM product_tags as pt WHERE pt.product_id = ` + productIdExpr + ` ORDER BY pt.tag)`
+}
+
 type ProductRepository interface {
 	FindAll(ctx context.Context, filter entity.GetAllProductFilter) ([]entity.Product, *common.Meta, int, error)
 	FindByID(ctx context.Context, id int64) (*entity.Product, int, error)
-	DeleteByID(ctx context.Context, id int64) (int, error)
+	StreamByUserID(ctx context.Context, userId int64, fn func(entity.Product) error) (int, error)
+	UpdateStocks(ctx context.Context, userId int64, updates []entity.ProductStockUpdate) ([]entity.ProductStockUpdateResult, int, error)
+	FindVersions(ctx context.Context, filter entity.GetProductVersionFilter) ([]entity.ProductVersion, *common.Meta, int, error)
+	FindPriceHistory(ctx context.Context, productId int64, limit int) ([]entity.ProductPrice, int, error)
+	DeleteByID(ctx context.Context, id int64, version int, userId int64) (int, error)
+	RestoreByID(ctx context.Context, id int64, userId int64) (int, error)
+	PurgeDeleted(ctx context.Context, deletedBefore int64) (int64, int, error)
 	UpdateByID(ctx context.Context, entity entity.Product) (*entity.Product, int, error)
-	UpdateStockByID(ctx context.Context, id int64, stock int) (int, error)
+	UpdateStockByID(ctx context.Context, id int64, stock int, version int, userId int64) (int, error)
+	UpdateVariantStockByID(ctx context.Context, productId int64, variantId int64, stock int, version int, userId int64) (int, error)
 	Create(ctx context.Context, entity entity.Product) (*entity.Product, int, error)
+	CreateMany(ctx context.Context, products []entity.Product) ([]entity.Product, int, error)
 	GetTotalSoldByUserId(ctx context.Context, userId int64) (int, int, error)
 	Purchase(ctx context.Context, id int64, amount int) (int, error)
+	Suggest(ctx context.Context, filter entity.GetSuggestionFilter) ([]entity.Suggestion, int, error)
+	FindFacets(ctx context.Context, filter entity.GetAllProductFilter, facets []string) (*entity.ProductFacets, int, error)
 }
 
 func NewProductRepository(logger zerolog.Logger, db *sql.DB) ProductRepository {
@@ -38,9 +56,11 @@ type ProductRepositoryImpl struct {
 	db     *sql.DB
 }
 
-func (r *ProductRepositoryImpl) FindAll(ctx context.Context, filter entity.GetAllProductFilter) ([]entity.Product, *common.Meta, int, error) {
-	var conditions []string
-	var args []interface{}
+// productFilterClause builds the WHERE clause shared by the product listing and its facets.
+// searchQuery is the parsed full-text query placeholder, empty when not searching
+func productFilterClause(filter entity.GetAllProductFilter) (whereClause string, args []interface{}, searchQuery string) {
+	// soft deleted products are never listed
+	conditions := []string{"deleted_at IS NULL"}
 	// Add conditions based on filter criteria
 	argIndex := 1 // Start index for placeholder arguments
 	if filter.UserOnly && filter.UserID != 0 {
@@ -50,13 +70,21 @@ func (r *ProductRepositoryImpl) FindAll(ctx context.Context, filter entity.GetAl
 	}
 
 	if len(filter.Tags) > 0 {
-		tagConditions := make([]string, len(filter.Tags))
-		for i, tag := range filter.Tags {
-			tagConditions[i] = "LOWER(tags) LIKE $" + fmt.Sprint(argIndex)
-			args = append(args, "%"+strings.ToLower(tag)+"%")
+		if filter.TagMatch == entity.TagMatchAny {
+			conditions = append(conditions, "EXISTS (SELECT 1 FROM product_tags as pt WHERE pt.product_id = products.id AND pt.tag = ANY($"+fmt.Sprint(argIndex)+"))")
+			args = append(args, pq.Array(filter.Tags))
 			argIndex++
+		} else {
+			conditions = append(conditions, "(SELECT COUNT(*) FROM product_tags as pt WHERE pt.product_id = products.id AND pt.tag = ANY($"+fmt.Sprint(argIndex)+")) = $"+fmt.Sprint(argIndex+1))
+			args = append(args, pq.Array(filter.Tags), len(filter.Tags))
+			argIndex += 2
 		}
-		conditions = append(conditions, "("+strings.Join(tagConditions, " AND ")+")")
+	}
+
+	if filter.CategoryID != 0 {
+		conditions = append(conditions, "category_id IN ("+categoryDescendantsQuery("$"+fmt.Sprint(argIndex))+")")
+		args = append(args, filter.CategoryID)
+		argIndex++
 	}
 
 	if filter.Condition != "" {
@@ -66,7 +94,7 @@ func (r *ProductRepositoryImpl) FindAll(ctx context.Context, filter entity.GetAl
 	}
 
 	if !filter.ShowEmptyStock {
-		conditions = append(conditions, "stock > 0")
+		conditions = append(conditions, "stock - "+reservedStockQuery("products.id")+" > 0")
 	}
 
 	if filter.MaxPrice > 0 {
@@ -82,31 +110,173 @@ func (r *ProductRepositoryImpl) FindAll(ctx context.Context, filter entity.GetAl
 	}
 
 	if filter.Search != "" {
-		conditions = append(conditions, "LOWER(name) LIKE $"+fmt.Sprint(argIndex))
-		args = append(args, "%"+strings.ToLower(filter.Search)+"%")
+		searchQuery = "websearch_to_tsquery('english', $" + fmt.Sprint(argIndex) + ")"
+		conditions = append(conditions, "search_vector @@ "+searchQuery)
+		args = append(args, filter.Search)
 		argIndex++
 	}
 
 	// Construct the WHERE clause
-	var whereClause string
 	if len(conditions) > 0 {
 		whereClause = "WHERE " + strings.Join(conditions, " AND ")
 	}
 
+	return whereClause, args, searchQuery
+}
+
+// productSortKey is one ORDER BY expression of the product listing
+type productSortKey struct {
+	expr string
+	desc bool
+}
+
+// trendingScoreQuery ranks products by their purchases with a boost for newer ones.
+// A product needs ten times the purchases to outrank one listed 12.5 hours later; the score does not
+// depend on the current time, so positions stay stable between cursor pages
+const trendingScoreQuery = "(LOG(purchase_count + 1) + created_at / 45000000.0)::float8"
+
+// productSortKeys returns the ordering of the product listing, always ending with id so every product has a stable position
+func productSortKeys(filter entity.GetAllProductFilter, searchQuery string) []productSortKey {
+	keys := []productSortKey{}
+	for _, sort := range filter.Sorts {
+		key := productSortKey{desc: sort.Desc}
+		switch sort.Field {
+		case entity.ProductSortDate:
+			key.expr = "created_at"
+		case entity.ProductSortPrice:
+			key.expr = "price"
+		case entity.ProductSortPurchaseCount:
+			key.expr = "purchase_count"
+		case entity.ProductSortStock:
+			key.expr = "(stock - " + reservedStockQuery("products.id") + ")"
+		case entity.ProductSortName:
+			key.expr = "LOWER(name)"
+		case entity.ProductSortTrending:
+			key.expr = trendingScoreQuery
+		case entity.ProductSortRelevance:
+			// without a search term every product is equally relevant, fall back to the listing date
+			if searchQuery != "" {
+				key.expr = "ts_rank(search_vector, " + searchQuery + ")"
+			} else {
+				key.expr = "created_at"
+			}
+		default:
+			continue
+		}
+		keys = append(keys, key)
+	}
+
+	desc := len(keys) > 0 && keys[0].desc
+	return append(keys, productSortKey{expr: "id", desc: desc})
+}
+
+// productSortSignature identifies the ordering a cursor was issued for
+func productSortSignature(sorts []entity.ProductSort) string {
+	fields := make([]string, len(sorts))
+	for i, sort := range sorts {
+		fields[i] = sort.Field
+		if sort.Desc {
+			fields[i] = "-" + sort.Field
+		}
+	}
+	return strings.Join(fields, ",")
+}
+
+// keysetCondition matches the rows ordered after the position whose sort values are bound from $argIndex onwards
+func keysetCondition(keys []productSortKey, argIndex int) string {
+	alternatives := make([]string, len(keys))
+	for i, key := range keys {
+		parts := []string{}
+		for j := 0; j < i; j++ {
+			parts = append(parts, keys[j].expr+" = $"+fmt.Sprint(argIndex+j))
+		}
+		op := " > $"
+		if key.desc {
+			op = " < $"
+		}
+		parts = append(parts, key.expr+op+fmt.Sprint(argIndex+i))
+		alternatives[i] = "(" + strings.Join(parts, " AND ") + ")"
+	}
+	return "(" + strings.Join(alternatives, " OR ") + ")"
+}
+
+// productCursor is the opaque position of a product page, Sort guards against reusing it with another ordering
+type productCursor struct {
+	Sort   string   `json:"s"`
+	Values []string `json:"v"`
+}
+
+func encodeProductCursor(cursor productCursor) string {
+	b, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeProductCursor(s string) (*productCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	cursor := productCursor{}
+	if err := json.Unmarshal(b, &cursor); err != nil {
+		return nil, err
+	}
+	return &cursor, nil
+}
+
+// cursorValues formats the scanned sort values so they can be bound back as query arguments
+func cursorValues(values []interface{}) []string {
+	list := make([]string, len(values))
+	for i, v := range values {
+		if b, ok := v.([]byte); ok {
+			list[i] = string(b)
+		} else {
+			list[i] = fmt.Sprint(v)
+		}
+	}
+	return list
+}
+
+func (r *ProductRepositoryImpl) FindAll(ctx context.Context, filter entity.GetAllProductFilter) ([]entity.Product, *common.Meta, int, error) {
+	whereClause, args, searchQuery := productFilterClause(filter)
+	argIndex := len(args) + 1 // Start index for the remaining placeholder arguments
+
 	// Construct the ORDER BY clause
-	var orderByClause string
-	if filter.SortBy != "" {
-		if filter.SortBy == "date" {
-			orderByClause = "ORDER BY " + "created_at"
+	sortKeys := productSortKeys(filter, searchQuery)
+	orderBys := make([]string, len(sortKeys))
+	sortSelects := make([]string, len(sortKeys))
+	for i, key := range sortKeys {
+		orderBys[i] = key.expr + " ASC"
+		if key.desc {
+			orderBys[i] = key.expr + " DESC"
+		}
+		sortSelects[i] = ",\n\t\t\t" + key.expr
+	}
+	orderByClause := "ORDER BY " + strings.Join(orderBys, ", ")
+
+	// In cursor mode the page starts right after the cursor position instead of at an offset
+	sortSignature := productSortSignature(filter.Sorts)
+	if filter.Cursor != "" {
+		cursor, err := decodeProductCursor(filter.Cursor)
+		if err != nil || cursor.Sort != sortSignature || len(cursor.Values) != len(sortKeys) {
+			return nil, nil, http.StatusBadRequest, errors.Wrap(errorer.ErrBadRequest, "invalid cursor")
 		}
 
-		if filter.SortBy == "price" {
-			orderByClause = "ORDER BY " + "price"
+		condition := keysetCondition(sortKeys, argIndex)
+		if whereClause == "" {
+			whereClause = "WHERE " + condition
+		} else {
+			whereClause += " AND " + condition
 		}
-		orderBy := strings.ToUpper(filter.OrderBy)
-		if orderBy != "" && (orderBy == "ASC" || orderBy == "DESC") {
-			orderByClause += " " + orderBy
+		for _, v := range cursor.Values {
+			args = append(args, v)
 		}
+		argIndex += len(cursor.Values)
+		filter.Offset = 0
+	}
+
+	highlightSelect := "''"
+	if searchQuery != "" {
+		highlightSelect = "ts_headline('english', name || ' ' || array_to_string(" + productTagsQuery("products.id") + ", ' '), " + searchQuery + ", 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true')"
 	}
 
 	// Construct the LIMIT and OFFSET clauses
@@ -121,14 +291,17 @@ func (r *ProductRepositoryImpl) FindAll(ctx context.Context, filter entity.GetAl
 			name, 
 			price, 
 			image_url,
-			stock, 
+			stock - ` + reservedStockQuery("products.id") + `,
 			condition, 
-			tags,
+			` + productTagsQuery("products.id") + `,
+			category_id,
 			is_purchasable,
 			purchase_count, 
+			purchase_limit_per_buyer,
 			user_id,
 			created_at,
-			updated_at
+			updated_at,
+			` + highlightSelect + strings.Join(sortSelects, "") + `
 	FROM products ` + whereClause + " " + orderByClause + " " + limitOffsetClause
 
 	// Construct the query to get total product count
@@ -138,7 +311,8 @@ func (r *ProductRepositoryImpl) FindAll(ctx context.Context, filter entity.GetAl
 	// Execute the main query
 	argsQuery := []interface{}{}
 	argsQuery = append(argsQuery, args...)
-	argsQuery = append(argsQuery, filter.Limit)
+	// one extra row tells whether there is a next page
+	argsQuery = append(argsQuery, filter.Limit+1)
 	argsQuery = append(argsQuery, filter.Offset)
 	rows, err := r.db.QueryContext(ctx, query, argsQuery...)
 	if err != nil {
@@ -146,27 +320,43 @@ func (r *ProductRepositoryImpl) FindAll(ctx context.Context, filter entity.GetAl
 	}
 	defer rows.Close()
 
+	var lastSortValues []interface{}
+	hasMore := false
 	for rows.Next() {
 		prd := entity.Product{}
+		var categoryId sql.NullInt64
+		sortValues := make([]interface{}, len(sortKeys))
 		// Add more variables as needed for other columns
-		err := rows.Scan(
+		dest := []interface{}{
 			&prd.ID,
 			&prd.Name,
 			&prd.Price,
 			&prd.ImageURL,
 			&prd.Stock,
 			&prd.Condition,
-			&prd.Tags,
+			pq.Array(&prd.Tags),
+			&categoryId,
 			&prd.IsPurchasable,
 			&prd.PurchaseCount,
+			&prd.PurchaseLimit,
 			&prd.UserID,
 			&prd.CreatedAt,
 			&prd.UpdatedAt,
-		)
-		if err != nil {
+			&prd.Highlight,
+		}
+		for i := range sortValues {
+			dest = append(dest, &sortValues[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
 			return nil, nil, http.StatusInternalServerError, errors.Wrap(err, "failed to scan product")
 		}
+		prd.CategoryID = categoryId.Int64
+		if len(prds) == filter.Limit {
+			hasMore = true
+			break
+		}
 		prds = append(prds, prd)
+		lastSortValues = sortValues
 	}
 
 	// Check for errors from iterating over rows
@@ -174,61 +364,89 @@ func (r *ProductRepositoryImpl) FindAll(ctx context.Context, filter entity.GetAl
 		return nil, nil, http.StatusInternalServerError, errors.Wrap(err, "failed to iterate over rows")
 	}
 
-	// Execute the count query to get total product count
-	var totalCount int
-	err = r.db.QueryRowContext(ctx, countQuery, args...).Scan(&totalCount)
+	ids := make([]int64, len(prds))
+	for i, v := range prds {
+		ids[i] = v.ID
+	}
+	images, err := findProductImages(ctx, r.db, ids)
 	if err != nil {
-		return nil, nil, http.StatusInternalServerError, errors.Wrap(err, "failed to get total product count")
+		return nil, nil, http.StatusInternalServerError, errors.Wrap(err, "failed to get product images")
+	}
+	for i := range prds {
+		prds[i].Images = images[prds[i].ID]
 	}
 
 	meta := common.Meta{
-		Total:  totalCount,
 		Limit:  filter.Limit,
 		Offset: filter.Offset,
 	}
 
+	if hasMore {
+		meta.NextCursor = encodeProductCursor(productCursor{Sort: sortSignature, Values: cursorValues(lastSortValues)})
+	}
+
+	// Execute the count query to get total product count, skipped in cursor mode to keep pages cheap
+	if filter.Cursor == "" {
+		err = r.db.QueryRowContext(ctx, countQuery, args...).Scan(&meta.Total)
+		if err != nil {
+			return nil, nil, http.StatusInternalServerError, errors.Wrap(err, "failed to get total product count")
+		}
+	}
+
 	return prds, &meta, http.StatusOK, nil
 }
 
 func (r *ProductRepositoryImpl) FindByID(ctx context.Context, id int64) (*entity.Product, int, error) {
 	prd := entity.Product{}
 	usr := entity.User{}
+	var categoryId sql.NullInt64
 	query := `
 		SELECT 
 			p.id, 
 			p.name, 
+			p.description,
 			p.price, 
 			p.image_url,
-			p.stock, 
+			p.stock - ` + reservedStockQuery("p.id") + `,
 			p.condition, 
-			p.tags,
+			` + productTagsQuery("p.id") + `,
+			p.category_id,
 			p.is_purchasable,
 			p.purchase_count, 
+			p.purchase_limit_per_buyer,
 			p.user_id,
 			p.created_at,
 			p.updated_at,
+			p.version,
+			` + productHasVariantsQuery("p.id") + `,
 			u.name
 		FROM products as p
 		LEFT JOIN users as u ON p.user_id = u.id
-		WHERE p.id = $1
+		WHERE p.id = $1 AND p.deleted_at IS NULL
 		LIMIT 1
 	`
 	err := r.db.QueryRowContext(ctx, query, id).Scan(&prd.ID,
 		&prd.Name,
+		&prd.Description,
 		&prd.Price,
 		&prd.ImageURL,
 		&prd.Stock,
 		&prd.Condition,
-		&prd.Tags,
+		pq.Array(&prd.Tags),
+		&categoryId,
 		&prd.IsPurchasable,
 		&prd.PurchaseCount,
+		&prd.PurchaseLimit,
 		&prd.UserID,
 		&prd.CreatedAt,
 		&prd.UpdatedAt,
+		&prd.Version,
+		&prd.HasVariants,
 		&usr.Name,
 	)
 
 	prd.User = usr
+	prd.CategoryID = categoryId.Int64
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -237,11 +455,131 @@ func (r *ProductRepositoryImpl) FindByID(ctx context.Context, id int64) (*entity
 		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
 	}
 
+	if prd.HasVariants {
+		prd.Options, prd.Variants, err = r.findProductVariants(ctx, prd.ID)
+		if err != nil {
+			return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+		}
+	}
+
+	images, err := findProductImages(ctx, r.db, []int64{prd.ID})
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	prd.Images = images[prd.ID]
+
 	return &prd, http.StatusOK, nil
 }
 
-func (r *ProductRepositoryImpl) Create(ctx context.Context, entity entity.Product) (*entity.Product, int, error) {
+// StreamByUserID calls fn with every product of the seller in id order, reading them one at a time
+// from the database cursor. A fn error stops the iteration and is returned as is
+func (r *ProductRepositoryImpl) StreamByUserID(ctx context.Context, userId int64, fn func(entity.Product) error) (int, error) {
+	query := `
+		SELECT
+			p.id,
+			p.name,
+			p.description,
+			p.price,
+			p.image_url,
+			p.stock,
+			p.condition,
+			` + productTagsQuery("p.id") + `,
+			p.category_id,
+			p.is_purchasable,
+			p.purchase_count,
+			p.purchase_limit_per_buyer,
+			p.user_id,
+			p.created_at,
+			p.updated_at,
+			p.version
+		FROM products as p
+		WHERE p.user_id = $1 AND p.deleted_at IS NULL
+		ORDER BY p.id
+	`
+	rows, err := r.db.QueryContext(ctx, query, userId)
+	if err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	defer rows.Close()
 
+	for rows.Next() {
+		prd := entity.Product{}
+		var categoryId sql.NullInt64
+		if err := rows.Scan(
+			&prd.ID,
+			&prd.Name,
+			&prd.Description,
+			&prd.Price,
+			&prd.ImageURL,
+			&prd.Stock,
+			&prd.Condition,
+			pq.Array(&prd.Tags),
+			&categoryId,
+			&prd.IsPurchasable,
+			&prd.PurchaseCount,
+			&prd.PurchaseLimit,
+			&prd.UserID,
+			&prd.CreatedAt,
+			&prd.UpdatedAt,
+			&prd.Version,
+		); err != nil {
+			return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+		}
+		prd.CategoryID = categoryId.Int64
+
+		if err := fn(prd); err != nil {
+			return http.StatusInternalServerError, err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	return http.StatusOK, nil
+}
+
+func (r *ProductRepositoryImpl) Create(ctx context.Context, ent entity.Product) (*entity.Product, int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	defer tx.Rollback()
+
+	if err := createProductTx(ctx, tx, &ent); err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	return &ent, http.StatusOK, nil
+}
+
+// CreateMany inserts all the products in a single transaction, none is stored if one fails
+func (r *ProductRepositoryImpl) CreateMany(ctx context.Context, products []entity.Product) ([]entity.Product, int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	defer tx.Rollback()
+
+	for i := range products {
+		if err := createProductTx(ctx, tx, &products[i]); err != nil {
+			return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	return products, http.StatusOK, nil
+}
+
+// createProductTx inserts the product with its tags, variants, cover image, first price and first version
+// inside the given transaction and sets its ID and Version
+func createProductTx(ctx context.Context, tx *sql.Tx, ent *entity.Product) error {
 	query := `
 		Insert into products
 		(	
@@ -250,50 +588,85 @@ func (r *ProductRepositoryImpl) Create(ctx context.Context, entity entity.Produc
 			image_url,
 			stock, 
 			condition, 
-			tags,
+			category_id,
 			is_purchasable,
 			purchase_count, 
+			purchase_limit_per_buyer,
 			user_id,
 			created_at,
-			updated_at
+			updated_at,
+			description
 		)
-		Values($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) 
+		Values($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13) 
 		RETURNING id;
 	`
 
-	err := r.db.QueryRowContext(ctx, query, entity.Name, entity.Price,
-		entity.ImageURL, entity.Stock, entity.Condition, entity.Tags, entity.IsPurchasable,
-		entity.PurchaseCount, entity.UserID, entity.CreatedAt, entity.UpdatedAt).Scan(&entity.ID)
+	err := tx.QueryRowContext(ctx, query, ent.Name, ent.Price,
+		ent.ImageURL, ent.Stock, ent.Condition, nullableID(ent.CategoryID), ent.IsPurchasable,
+		ent.PurchaseCount, ent.PurchaseLimit, ent.UserID, ent.CreatedAt, ent.UpdatedAt,
+		ent.Description).Scan(&ent.ID)
+	if err != nil {
+		return err
+	}
 
+	if err := replaceProductTagsTx(ctx, tx, ent.ID, ent.Tags); err != nil {
+		return err
+	}
+	if err := replaceProductVariantsTx(ctx, tx, *ent, false); err != nil {
+		return err
+	}
+	if err := setCoverImageTx(ctx, tx, ent.ID, ent.ImageURL, ent.CreatedAt); err != nil {
+		return err
+	}
+	if err := recordPriceChangeTx(ctx, tx, *ent, -1, ent.CreatedAt); err != nil {
+		return err
+	}
+	ent.Version, err = recordProductVersionTx(ctx, tx, ent.ID, entity.ProductVersionActionCreate, ent.UserID, ent.CreatedAt)
+	return err
+}
+
+func (r *ProductRepositoryImpl) UpdateByID(ctx context.Context, ent entity.Product) (*entity.Product, int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
 	}
+	defer tx.Rollback()
+
+	// ent.Version is the version the update is based on
+	if code, err := lockProductVersionTx(ctx, tx, ent.ID, ent.Version); err != nil {
+		return nil, code, err
+	}
 
-	return &entity, http.StatusOK, nil
-}
+	var previousPrice int
+	if err := tx.QueryRowContext(ctx, `SELECT price FROM products WHERE id = $1`, ent.ID).Scan(&previousPrice); err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
 
-func (r *ProductRepositoryImpl) UpdateByID(ctx context.Context, entity entity.Product) (*entity.Product, int, error) {
 	query := `
 		UPDATE products SET
 			name=$1, 
 			price=$2, 
 			image_url=$3,
 			condition=$4, 
-			tags=$5,
+			category_id=$5,
 			is_purchasable=$6,
-			updated_at=$7
-		Where id = $8
+			purchase_limit_per_buyer=$7,
+			updated_at=$8,
+			description=$10
+		Where id = $9 AND deleted_at IS NULL
 	`
 
-	res, err := r.db.ExecContext(ctx, query,
-		entity.Name,
-		entity.Price,
-		entity.ImageURL,
-		entity.Condition,
-		entity.Tags,
-		entity.IsPurchasable,
-		entity.UpdatedAt,
-		entity.ID)
+	res, err := tx.ExecContext(ctx, query,
+		ent.Name,
+		ent.Price,
+		ent.ImageURL,
+		ent.Condition,
+		nullableID(ent.CategoryID),
+		ent.IsPurchasable,
+		ent.PurchaseLimit,
+		ent.UpdatedAt,
+		ent.ID,
+		ent.Description)
 
 	if err != nil {
 		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
@@ -308,20 +681,55 @@ func (r *ProductRepositoryImpl) UpdateByID(ctx context.Context, entity entity.Pr
 		return nil, http.StatusNotFound, errors.Wrap(errorer.ErrNotFound, errorer.ErrNotFound.Error())
 	}
 
-	return &entity, http.StatusOK, nil
+	if err := replaceProductTagsTx(ctx, tx, ent.ID, ent.Tags); err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	// nil variants mean the request left them out, an empty list removes them
+	if ent.Variants != nil {
+		if err := replaceProductVariantsTx(ctx, tx, ent, true); err != nil {
+			return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+		}
+	}
+	if err := setCoverImageTx(ctx, tx, ent.ID, ent.ImageURL, ent.UpdatedAt); err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	if err := recordPriceChangeTx(ctx, tx, ent, previousPrice, ent.UpdatedAt); err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	// the owner is the only one allowed to update, so UserID is the acting user
+	if ent.Version, err = recordProductVersionTx(ctx, tx, ent.ID, entity.ProductVersionActionUpdate, ent.UserID, ent.UpdatedAt); err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	return &ent, http.StatusOK, nil
 }
 
-func (r *ProductRepositoryImpl) UpdateStockByID(ctx context.Context, id int64, stock int) (int, error) {
+func (r *ProductRepositoryImpl) UpdateStockByID(ctx context.Context, id int64, stock int, version int, userId int64) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	defer tx.Rollback()
+
+	if code, err := lockProductVersionTx(ctx, tx, id, version); err != nil {
+		return code, err
+	}
+
+	updatedAt := time.Now().UnixMilli()
 	query := `
 		UPDATE products SET
 			stock=$1, 
 			updated_at=$2
-		Where id = $3
+		Where id = $3 AND deleted_at IS NULL
 	`
 
-	res, err := r.db.ExecContext(ctx, query,
+	res, err := tx.ExecContext(ctx, query,
 		stock,
-		time.Now().UnixMilli(),
+		updatedAt,
 		id,
 	)
 
@@ -338,22 +746,279 @@ func (r *ProductRepositoryImpl) UpdateStockByID(ctx context.Context, id int64, s
 		return http.StatusNotFound, errors.Wrap(errorer.ErrNotFound, errorer.ErrNotFound.Error())
 	}
 
+	if _, err := recordProductVersionTx(ctx, tx, id, entity.ProductVersionActionStock, userId, updatedAt); err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	if err := tx.Commit(); err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
 	return http.StatusOK, nil
 }
 
-func (r *ProductRepositoryImpl) DeleteByID(ctx context.Context, id int64) (int, error) {
-	query := `DELETE FROM products WHERE id = $1 RETURNING id`
-	rId := 0
-	if err := r.db.QueryRowContext(ctx, query, id).Scan(&rId); err != nil {
+// DeleteByID soft deletes the product, it stays in the database for the payments referencing it
+func (r *ProductRepositoryImpl) DeleteByID(ctx context.Context, id int64, version int, userId int64) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	defer tx.Rollback()
+
+	if code, err := lockProductVersionTx(ctx, tx, id, version); err != nil {
+		return code, err
+	}
+
+	deletedAt := time.Now().UnixMilli()
+	query := `UPDATE products SET deleted_at = $2 WHERE id = $1 AND deleted_at IS NULL`
+	res, err := tx.ExecContext(ctx, query, id, deletedAt)
+	if err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	row, err := res.RowsAffected()
+	if err != nil {
 		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
 	}
-	if rId == 0 {
+	if row == 0 {
 		return http.StatusNotFound, errors.Wrap(errorer.ErrNotFound, "product not found")
 	}
 
+	if _, err := recordProductVersionTx(ctx, tx, id, entity.ProductVersionActionDelete, userId, deletedAt); err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	if err := tx.Commit(); err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	return http.StatusOK, nil
+}
+
+// RestoreByID undoes the soft deletion of a product owned by the user
+func (r *ProductRepositoryImpl) RestoreByID(ctx context.Context, id int64, userId int64) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	defer tx.Rollback()
+
+	var ownerId int64
+	var deletedAt sql.NullInt64
+	err = tx.QueryRowContext(ctx, `SELECT user_id, deleted_at FROM products WHERE id = $1 FOR UPDATE`, id).Scan(&ownerId, &deletedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return http.StatusNotFound, errors.Wrap(errorer.ErrNotFound, "product not found")
+		}
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	if ownerId != userId {
+		return http.StatusForbidden, errors.Wrap(errorer.ErrForbidden, errorer.ErrForbidden.Error())
+	}
+	if !deletedAt.Valid {
+		return http.StatusNotFound, errors.Wrap(errorer.ErrNotFound, "product is not deleted")
+	}
+
+	restoredAt := time.Now().UnixMilli()
+	_, err = tx.ExecContext(ctx, `UPDATE products SET deleted_at = NULL, updated_at = $2 WHERE id = $1`, id, restoredAt)
+	if err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	if _, err := recordProductVersionTx(ctx, tx, id, entity.ProductVersionActionRestore, userId, restoredAt); err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	if err := tx.Commit(); err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
 	return http.StatusOK, nil
 }
 
+// PurgeDeleted hard deletes the products soft deleted before the given time. Products referenced
+// by payments are kept so past orders can still be resolved
+func (r *ProductRepositoryImpl) PurgeDeleted(ctx context.Context, deletedBefore int64) (int64, int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	defer tx.Rollback()
+
+	purgeable := `
+		SELECT id FROM products
+		WHERE deleted_at < $1
+			AND NOT EXISTS (SELECT 1 FROM payments WHERE payments.product_id = products.id)
+	`
+	if _, err := tx.ExecContext(ctx, `DELETE FROM cart_items WHERE product_id IN (`+purgeable+`)`, deletedBefore); err != nil {
+		return 0, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM reservations WHERE product_id IN (`+purgeable+`)`, deletedBefore); err != nil {
+		return 0, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	res, err := tx.ExecContext(ctx, `DELETE FROM products WHERE id IN (`+purgeable+`)`, deletedBefore)
+	if err != nil {
+		return 0, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	purged, err := res.RowsAffected()
+	if err != nil {
+		return 0, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	return purged, http.StatusOK, nil
+}
+
+const (
+	// facetTopTagsLimit is the number of most used tags returned in the tags facet
+	facetTopTagsLimit = 10
+	// facetPriceBuckets is the number of equal width buckets of the price histogram
+	facetPriceBuckets = 5
+)
+
+// FindFacets counts the products matching the filter by condition, tag, price bucket and stock availability
+func (r *ProductRepositoryImpl) FindFacets(ctx context.Context, filter entity.GetAllProductFilter, facets []string) (*entity.ProductFacets, int, error) {
+	whereClause, args, _ := productFilterClause(filter)
+	argIndex := len(args) + 1
+	res := entity.ProductFacets{}
+
+	for _, facet := range facets {
+		var err error
+		switch facet {
+		case entity.FacetCondition:
+			res.Conditions, err = r.findFacetCounts(ctx, `
+				SELECT condition, COUNT(*)
+				FROM products `+whereClause+`
+				GROUP BY condition
+				ORDER BY COUNT(*) DESC, condition
+			`, args...)
+		case entity.FacetTags:
+			res.Tags, err = r.findFacetCounts(ctx, `
+				SELECT pt.tag, COUNT(*)
+				FROM product_tags as pt
+				WHERE pt.product_id IN (SELECT id FROM products `+whereClause+`)
+				GROUP BY pt.tag
+				ORDER BY COUNT(*) DESC, pt.tag
+				LIMIT $`+fmt.Sprint(argIndex), append(args, facetTopTagsLimit)...)
+		case entity.FacetPrice:
+			res.PriceBuckets, err = r.findPriceBuckets(ctx, whereClause, argIndex, args)
+		case entity.FacetStock:
+			// the stock facet must not be narrowed by the stock filter it counts
+			stockFilter := filter
+			stockFilter.ShowEmptyStock = true
+			stockClause, stockArgs, _ := productFilterClause(stockFilter)
+			stock := entity.StockFacet{}
+			err = r.db.QueryRowContext(ctx, `
+				SELECT
+					COUNT(*) FILTER (WHERE stock - `+reservedStockQuery("products.id")+` > 0),
+					COUNT(*) FILTER (WHERE stock - `+reservedStockQuery("products.id")+` <= 0)
+				FROM products `+stockClause, stockArgs...).Scan(&stock.InStock, &stock.OutOfStock)
+			res.Stock = &stock
+		}
+		if err != nil {
+			return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+		}
+	}
+
+	return &res, http.StatusOK, nil
+}
+
+func (r *ProductRepositoryImpl) findFacetCounts(ctx context.Context, query string, args ...interface{}) ([]entity.FacetCount, error) {
+	counts := []entity.FacetCount{}
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		count := entity.FacetCount{}
+		if err := rows.Scan(&count.Value, &count.Count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, count)
+	}
+
+	return counts, rows.Err()
+}
+
+// findPriceBuckets splits the price range of the matching products into equal width buckets, empty buckets are omitted
+func (r *ProductRepositoryImpl) findPriceBuckets(ctx context.Context, whereClause string, argIndex int, args []interface{}) ([]entity.PriceBucket, error) {
+	buckets := []entity.PriceBucket{}
+	query := `
+		WITH matched as (
+			SELECT price FROM products ` + whereClause + `
+		), bounds as (
+			SELECT MIN(price)::bigint as lo, GREATEST(CEIL((MAX(price) - MIN(price) + 1)::numeric / $` + fmt.Sprint(argIndex) + `), 1)::int as width
+			FROM matched
+		)
+		SELECT (lo + bucket * width)::bigint, (lo + (bucket + 1) * width - 1)::bigint, COUNT(*)
+		FROM (
+			SELECT FLOOR((m.price - b.lo) / b.width)::int as bucket, b.lo, b.width
+			FROM matched as m CROSS JOIN bounds as b
+		) as h
+		GROUP BY bucket, lo, width
+		ORDER BY bucket
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, append(args, facetPriceBuckets)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		bucket := entity.PriceBucket{}
+		if err := rows.Scan(&bucket.Min, &bucket.Max, &bucket.Count); err != nil {
+			return nil, err
+		}
+		buckets = append(buckets, bucket)
+	}
+
+	return buckets, rows.Err()
+}
+
+// Suggest returns product names and tags ranked by their trigram similarity to the query
+func (r *ProductRepositoryImpl) Suggest(ctx context.Context, filter entity.GetSuggestionFilter) ([]entity.Suggestion, int, error) {
+	suggestions := []entity.Suggestion{}
+	query := `
+		SELECT term, kind, score FROM (
+			SELECT name as term, $3::text as kind, GREATEST(similarity(LOWER(name), $1), word_similarity($1, LOWER(name))) as score
+			FROM (SELECT DISTINCT name FROM products WHERE deleted_at IS NULL AND (LOWER(name) % $1 OR $1 <% LOWER(name))) as n
+			UNION ALL
+			SELECT tag as term, $4::text as kind, GREATEST(similarity(tag, $1), word_similarity($1, tag)) as score
+			FROM (
+				SELECT DISTINCT pt.tag FROM product_tags as pt
+				JOIN products as p ON pt.product_id = p.id AND p.deleted_at IS NULL
+				WHERE pt.tag % $1 OR $1 <% pt.tag
+			) as t
+		) as s
+		ORDER BY score DESC, term
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, strings.ToLower(filter.Query), filter.Limit, entity.SuggestionKindProduct, entity.SuggestionKindTag)
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		suggestion := entity.Suggestion{}
+		if err := rows.Scan(&suggestion.Term, &suggestion.Kind, &suggestion.Score); err != nil {
+			return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	return suggestions, http.StatusOK, nil
+}
+
 func (r *ProductRepositoryImpl) GetTotalSoldByUserId(ctx context.Context, userId int64) (int, int, error) {
 
 	var total int
@@ -372,21 +1037,73 @@ func (r *ProductRepositoryImpl) Purchase(ctx context.Context, id int64, amount i
 	}
 	defer tx.Rollback()
 
-	// Acquire a row-level lock on the product row for update
-	_, err = tx.ExecContext(ctx, `SELECT id FROM products WHERE id = $1 FOR UPDATE`, id)
+	_, code, err := purchaseProductTx(ctx, tx, id, 0, amount, 0)
 	if err != nil {
+		return code, err
+	}
+
+	if err := tx.Commit(); err != nil {
 		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
 	}
 
+	return http.StatusOK, nil
+}
+
+// purchaseProductTx locks the product row and decrements its stock inside the given transaction,
+// returning the product as it was read under the lock. Products with variants are bought through
+// one of them: its stock is decremented too, its price and image are returned and it is the only
+// entry of the returned product Variants. The purchase limit per buyer is enforced unless buyerId is 0
+func purchaseProductTx(ctx context.Context, tx *sql.Tx, id int64, variantId int64, amount int, buyerId int64) (*entity.Product, int, error) {
+	// Acquire a row-level lock on the product row for update
+	_, err := tx.ExecContext(ctx, `SELECT id FROM products WHERE id = $1 FOR UPDATE`, id)
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
 	prd := entity.Product{}
-	err = tx.QueryRowContext(ctx, `SELECT id, name, price, stock, purchase_count FROM products WHERE id = $1`, id).
-		Scan(&prd.ID, &prd.Name, &prd.Price, &prd.Stock, &prd.PurchaseCount)
+	var reserved int
+	err = tx.QueryRowContext(ctx, `SELECT id, name, price, image_url, stock, purchase_count, purchase_limit_per_buyer, user_id, version, `+reservedStockQuery("products.id")+`, `+productHasVariantsQuery("products.id")+` FROM products WHERE id = $1 AND deleted_at IS NULL`, id).
+		Scan(&prd.ID, &prd.Name, &prd.Price, &prd.ImageURL, &prd.Stock, &prd.PurchaseCount, &prd.PurchaseLimit, &prd.UserID, &prd.Version, &reserved, &prd.HasVariants)
 	if err != nil {
-		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+		if err == sql.ErrNoRows {
+			return nil, http.StatusNotFound, errors.Wrap(errorer.ErrNotFound, "product not found")
+		}
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	if prd.HasVariants && variantId == 0 {
+		return nil, http.StatusBadRequest, errors.Wrap(errorer.ErrInputRequest(errors.New("variant is required")), errorer.ErrInputRequest(errors.New("variant is required")).Error())
+	}
+
+	// units held by other buyers' active reservations are not available
+	if prd.Stock-reserved < amount {
+		return nil, http.StatusBadRequest, errors.Wrap(errorer.ErrInputRequest(errors.New("insufficient stock")), errorer.ErrInputRequest(errors.New("insufficient stock")).Error())
 	}
 
-	if prd.Stock < amount {
-		return http.StatusBadRequest, errors.Wrap(errorer.ErrInputRequest(errors.New("insufficient stock")), errorer.ErrInputRequest(errors.New("insufficient stock")).Error())
+	// concurrent purchases of the product wait on the row lock, so the sum includes every committed one
+	if buyerId != 0 && prd.PurchaseLimit > 0 {
+		purchased, err := sumQuantityByBuyer(ctx, tx, buyerId, prd.ID)
+		if err != nil {
+			return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+		}
+		if purchased+amount > prd.PurchaseLimit {
+			message := fmt.Sprintf("each buyer can purchase at most %d units of this product", prd.PurchaseLimit)
+			return nil, http.StatusBadRequest, errors.Wrap(errorer.ErrRejected(entity.PurchaseRejectedBuyerLimit, message), message)
+		}
+	}
+
+	if variantId != 0 {
+		variant, code, err := purchaseVariantTx(ctx, tx, prd.ID, variantId, amount)
+		if err != nil {
+			return nil, code, err
+		}
+		if variant.Price != nil {
+			prd.Price = *variant.Price
+		}
+		if variant.ImageURL != "" {
+			prd.ImageURL = variant.ImageURL
+		}
+		prd.Variants = []entity.ProductVariant{*variant}
 	}
 
 	prd.PurchaseCount += amount
@@ -395,12 +1112,33 @@ func (r *ProductRepositoryImpl) Purchase(ctx context.Context, id int64, amount i
 	_, err = tx.ExecContext(ctx, `UPDATE products SET stock = $1, purchase_count = $2 WHERE id = $3`, prd.Stock, prd.PurchaseCount, prd.ID)
 
 	if err != nil {
-		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
 	}
 
-	if err := tx.Commit(); err != nil {
-		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	return &prd, http.StatusOK, nil
+}
+
+// replaceProductTagsTx sets the tags of the product inside the given transaction
+func replaceProductTagsTx(ctx context.Context, tx *sql.Tx, productId int64, tags []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM product_tags WHERE product_id = $1`, productId); err != nil {
+		return err
 	}
 
-	return http.StatusOK, nil
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO product_tags (product_id, tag)
+		SELECT $1, UNNEST($2::text[])
+		ON CONFLICT DO NOTHING
+	`, productId, pq.Array(tags))
+	return err
+}
+
+// replaceProductVariantsTx stores the options and variants of the product and keeps its stock in line with them
+func replaceProductVariantsTx(ctx context.Context, tx *sql.Tx, prd entity.Product, keepStock bool) error {
+	if err := replaceProductOptionsTx(ctx, tx, prd.ID, prd.Options); err != nil {
+		return err
+	}
+	if err := upsertProductVariantsTx(ctx, tx, prd.ID, prd.Variants, keepStock, prd.UpdatedAt); err != nil {
+		return err
+	}
+	return syncVariantStockTx(ctx, tx, prd.ID)
 }
@@ -0,0 +1,269 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"ecomm/internal/helper/errorer"
+	"ecomm/internal/model/entity"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+type ProductImageRepository interface {
+	FindByProductID(ctx context.Context, productId int64) ([]entity.ProductImage, int, error)
+	Create(ctx context.Context, ent entity.ProductImage, maxImages int, userId int64) (*entity.ProductImage, int, error)
+	Reorder(ctx context.Context, productId int64, imageIds []int64, userId int64) (int, error)
+	DeleteByID(ctx context.Context, productId int64, id int64, userId int64) (int, error)
+}
+
+func NewProductImageRepository(logger zerolog.Logger, db *sql.DB) ProductImageRepository {
+	return &ProductImageRepositoryImpl{
+		logger: logger,
+		db:     db,
+	}
+}
+
+type ProductImageRepositoryImpl struct {
+	logger zerolog.Logger
+	db     *sql.DB
+}
+
+func (r *ProductImageRepositoryImpl) FindByProductID(ctx context.Context, productId int64) ([]entity.ProductImage, int, error) {
+	images, err := findProductImages(ctx, r.db, []int64{productId})
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	if images[productId] == nil {
+		return []entity.ProductImage{}, http.StatusOK, nil
+	}
+	return images[productId], http.StatusOK, nil
+}
+
+// Create appends the image at the end of the product gallery, unless it already holds maxImages images
+func (r *ProductImageRepositoryImpl) Create(ctx context.Context, ent entity.ProductImage, maxImages int, userId int64) (*entity.ProductImage, int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	defer tx.Rollback()
+
+	if code, err := lockProductTx(ctx, tx, ent.ProductID); err != nil {
+		return nil, code, err
+	}
+
+	// counted under the product lock so concurrent uploads cannot go over the limit
+	var count int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM product_images WHERE product_id = $1`, ent.ProductID).Scan(&count); err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	if count >= maxImages {
+		err := errorer.ErrInputRequest(fmt.Errorf("a product can have at most %d images", maxImages))
+		return nil, http.StatusBadRequest, errors.Wrap(err, err.Error())
+	}
+
+	query := `
+		INSERT INTO product_images (product_id, url, alt_text, position, created_at)
+		SELECT $1, $2, $3, COALESCE(MAX(position) + 1, 0), $4
+		FROM product_images
+		WHERE product_id = $1
+		RETURNING id, position
+	`
+	err = tx.QueryRowContext(ctx, query, ent.ProductID, ent.URL, ent.AltText, ent.CreatedAt).Scan(&ent.ID, &ent.Position)
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	if err := syncCoverImageTx(ctx, tx, ent.ProductID); err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	if _, err := recordProductVersionTx(ctx, tx, ent.ProductID, entity.ProductVersionActionUpdate, userId, ent.CreatedAt); err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	return &ent, http.StatusOK, nil
+}
+
+// Reorder moves the images to the position of their id in imageIds, which must list every image of the product
+func (r *ProductImageRepositoryImpl) Reorder(ctx context.Context, productId int64, imageIds []int64, userId int64) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	defer tx.Rollback()
+
+	if code, err := lockProductTx(ctx, tx, productId); err != nil {
+		return code, err
+	}
+
+	var matching, total int
+	err = tx.QueryRowContext(ctx, `
+		SELECT COUNT(*) FILTER (WHERE id = ANY($2)), COUNT(*)
+		FROM product_images
+		WHERE product_id = $1
+	`, productId, pq.Array(imageIds)).Scan(&matching, &total)
+	if err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	if matching != len(imageIds) || total != len(imageIds) {
+		return http.StatusBadRequest, errors.Wrap(errorer.ErrInputRequest(errors.New("imageIds must list every image of the product once")), "imageIds must list every image of the product once")
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE product_images SET position = o.position - 1
+		FROM UNNEST($2::int[]) WITH ORDINALITY as o(id, position)
+		WHERE product_images.id = o.id AND product_images.product_id = $1
+	`, productId, pq.Array(imageIds))
+	if err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	if err := syncCoverImageTx(ctx, tx, productId); err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	if _, err := recordProductVersionTx(ctx, tx, productId, entity.ProductVersionActionUpdate, userId, time.Now().UnixMilli()); err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	if err := tx.Commit(); err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	return http.StatusOK, nil
+}
+
+// DeleteByID removes the image and closes the gap in the positions, the last image of a product cannot be removed
+func (r *ProductImageRepositoryImpl) DeleteByID(ctx context.Context, productId int64, id int64, userId int64) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	defer tx.Rollback()
+
+	if code, err := lockProductTx(ctx, tx, productId); err != nil {
+		return code, err
+	}
+
+	var total int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM product_images WHERE product_id = $1`, productId).Scan(&total); err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM product_images WHERE id = $1 AND product_id = $2`, id, productId)
+	if err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	row, err := res.RowsAffected()
+	if err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	if row == 0 {
+		return http.StatusNotFound, errors.Wrap(errorer.ErrNotFound, "image not found")
+	}
+	if total <= 1 {
+		return http.StatusConflict, errors.Wrap(errorer.ErrConflict, "a product needs at least one image")
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE product_images SET position = o.position
+		FROM (
+			SELECT id, ROW_NUMBER() OVER (ORDER BY position, id) - 1 as position
+			FROM product_images
+			WHERE product_id = $1
+		) as o
+		WHERE product_images.id = o.id
+	`, productId)
+	if err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	if err := syncCoverImageTx(ctx, tx, productId); err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	if _, err := recordProductVersionTx(ctx, tx, productId, entity.ProductVersionActionUpdate, userId, time.Now().UnixMilli()); err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	if err := tx.Commit(); err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	return http.StatusOK, nil
+}
+
+// findProductImages returns the ordered gallery of each of the given products
+func findProductImages(ctx context.Context, db *sql.DB, productIds []int64) (map[int64][]entity.ProductImage, error) {
+	images := map[int64][]entity.ProductImage{}
+	rows, err := db.QueryContext(ctx, `
+		SELECT id, product_id, url, alt_text, position, created_at
+		FROM product_images
+		WHERE product_id = ANY($1)
+		ORDER BY product_id, position, id
+	`, pq.Array(productIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		image := entity.ProductImage{}
+		if err := rows.Scan(&image.ID, &image.ProductID, &image.URL, &image.AltText, &image.Position, &image.CreatedAt); err != nil {
+			return nil, err
+		}
+		images[image.ProductID] = append(images[image.ProductID], image)
+	}
+
+	return images, rows.Err()
+}
+
+// lockProductTx locks the product row so concurrent changes to its gallery are applied one at a time
+func lockProductTx(ctx context.Context, tx *sql.Tx, productId int64) (int, error) {
+	var id int64
+	err := tx.QueryRowContext(ctx, `SELECT id FROM products WHERE id = $1 FOR UPDATE`, productId).Scan(&id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return http.StatusNotFound, errors.Wrap(errorer.ErrNotFound, "product not found")
+		}
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	return http.StatusOK, nil
+}
+
+// setCoverImageTx replaces the url of the first image of the product, or adds it when the gallery is empty
+func setCoverImageTx(ctx context.Context, tx *sql.Tx, productId int64, url string, createdAt int64) error {
+	res, err := tx.ExecContext(ctx, `
+		UPDATE product_images SET url = $2
+		WHERE id = (SELECT id FROM product_images WHERE product_id = $1 ORDER BY position, id LIMIT 1)
+	`, productId, url)
+	if err != nil {
+		return err
+	}
+	row, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if row > 0 {
+		return nil
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO product_images (product_id, url, position, created_at) VALUES ($1, $2, 0, $3)`, productId, url, createdAt)
+	return err
+}
+
+// syncCoverImageTx copies the url of the first image of the gallery to the product image_url
+func syncCoverImageTx(ctx context.Context, tx *sql.Tx, productId int64) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE products SET
+			image_url = (SELECT url FROM product_images WHERE product_id = $1 ORDER BY position, id LIMIT 1)
+		WHERE id = $1 AND EXISTS (SELECT 1 FROM product_images WHERE product_id = $1)
+	`, productId)
+	return err
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"ecomm/internal/helper/errorer"
+	"ecomm/internal/model/entity"
+	"fmt"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// FindPriceHistory returns the latest price changes of the product, oldest first
+func (r *ProductRepositoryImpl) FindPriceHistory(ctx context.Context, productId int64, limit int) ([]entity.ProductPrice, int, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT price, created_at FROM (
+			SELECT price, created_at, id
+			FROM product_prices
+			WHERE product_id = $1
+			ORDER BY created_at DESC, id DESC
+			LIMIT $2
+		) as h
+		ORDER BY created_at, id
+	`, productId, limit)
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	defer rows.Close()
+
+	prices := []entity.ProductPrice{}
+	for rows.Next() {
+		price := entity.ProductPrice{}
+		if err := rows.Scan(&price.Price, &price.CreatedAt); err != nil {
+			return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+		}
+		prices = append(prices, price)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	return prices, http.StatusOK, nil
+}
+
+// recordPriceChangeTx adds the product price to its history when it differs from previousPrice, a negative
+// previousPrice meaning a new product. On a price drop the buyers whose alert threshold is now beaten are
+// notified and their alerts removed
+func recordPriceChangeTx(ctx context.Context, tx *sql.Tx, prd entity.Product, previousPrice int, changedAt int64) error {
+	if prd.Price == previousPrice {
+		return nil
+	}
+
+	_, err := tx.ExecContext(ctx, `INSERT INTO product_prices (product_id, price, created_at) VALUES ($1, $2, $3)`, prd.ID, prd.Price, changedAt)
+	if err != nil || previousPrice < 0 || prd.Price > previousPrice {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		WITH triggered as (
+			DELETE FROM price_alerts
+			WHERE product_id = $1 AND target_price > $2
+			RETURNING user_id
+		)
+		INSERT INTO notifications (user_id, type, product_id, message, created_at)
+		SELECT user_id, $3, $1, $4, $5 FROM triggered
+	`, prd.ID, prd.Price, entity.NotificationTypePriceDrop,
+		fmt.Sprintf("The price of %s dropped from %d to %d", prd.Name, previousPrice, prd.Price), changedAt)
+	return err
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"ecomm/internal/helper/errorer"
+	"ecomm/internal/model/entity"
+	"net/http"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+// UpdateStocks sets the stock, and the price when given, of several products of the user in a single
+// transaction. Every item is checked before anything is written, including its version as lockProductVersionTx
+// does, the first refused item aborts the batch and its code and error are returned along with the result of every item
+func (r *ProductRepositoryImpl) UpdateStocks(ctx context.Context, userId int64, updates []entity.ProductStockUpdate) ([]entity.ProductStockUpdateResult, int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	defer tx.Rollback()
+
+	ids := make([]int64, len(updates))
+	for i, u := range updates {
+		ids[i] = u.ProductID
+	}
+
+	// ownership of every product is read with one query, rows are locked in id order to avoid deadlocks
+	// between overlapping batches
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, name, price, user_id, version, `+productHasVariantsQuery("products.id")+`
+		FROM products
+		WHERE id = ANY($1) AND deleted_at IS NULL
+		ORDER BY id
+		FOR UPDATE
+	`, pq.Array(ids))
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	defer rows.Close()
+
+	products := make(map[int64]entity.Product, len(updates))
+	for rows.Next() {
+		prd := entity.Product{}
+		if err := rows.Scan(&prd.ID, &prd.Name, &prd.Price, &prd.UserID, &prd.Version, &prd.HasVariants); err != nil {
+			return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+		}
+		products[prd.ID] = prd
+	}
+	if err := rows.Err(); err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	rows.Close()
+
+	results := make([]entity.ProductStockUpdateResult, len(updates))
+	code, refused := http.StatusOK, error(nil)
+	for i, u := range updates {
+		prd, ok := products[u.ProductID]
+		results[i] = entity.ProductStockUpdateResult{ProductID: u.ProductID, Stock: u.Stock, Price: prd.Price, Code: http.StatusOK}
+		if u.Price != nil {
+			results[i].Price = *u.Price
+		}
+
+		switch {
+		case !ok:
+			results[i].Code, results[i].Err = http.StatusNotFound, errors.Wrap(errorer.ErrNotFound, "product not found")
+		case prd.UserID != userId:
+			results[i].Code, results[i].Err = http.StatusForbidden, errors.Wrap(errorer.ErrForbidden, errorer.ErrForbidden.Error())
+		case prd.Version != u.Version:
+			results[i].Code, results[i].Err = http.StatusPreconditionFailed, errors.Wrap(errorer.ErrPreconditionFailed, "product has been modified since it was read")
+		case prd.HasVariants:
+			err := errorer.ErrInputRequest(errors.New("the stock of a product with variants is updated per variant"))
+			results[i].Code, results[i].Err = http.StatusBadRequest, errors.Wrap(err, err.Error())
+		}
+		if results[i].Err != nil && refused == nil {
+			code, refused = results[i].Code, results[i].Err
+		}
+	}
+	if refused != nil {
+		return results, code, refused
+	}
+
+	updatedAt := time.Now().UnixMilli()
+	for i := range results {
+		prd := products[results[i].ProductID]
+		_, err := tx.ExecContext(ctx, `UPDATE products SET stock = $1, price = $2, updated_at = $3 WHERE id = $4`,
+			results[i].Stock, results[i].Price, updatedAt, prd.ID)
+		if err != nil {
+			return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+		}
+
+		action := entity.ProductVersionActionStock
+		if results[i].Price != prd.Price {
+			action = entity.ProductVersionActionUpdate
+			previousPrice := prd.Price
+			prd.Price = results[i].Price
+			if err := recordPriceChangeTx(ctx, tx, prd, previousPrice, updatedAt); err != nil {
+				return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+			}
+		}
+
+		results[i].Version, err = recordProductVersionTx(ctx, tx, prd.ID, action, userId, updatedAt)
+		if err != nil {
+			return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	return results, http.StatusOK, nil
+}
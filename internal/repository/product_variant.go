@@ -0,0 +1,222 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"ecomm/internal/helper/errorer"
+	"ecomm/internal/model/entity"
+	"net/http"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+// productHasVariantsQuery tells whether the product referenced by the given SQL expression is sold through variants
+func productHasVariantsQuery(productIdExpr string) string {
+	return `EXISTS (SELECT 1 FROM product_variants as pv WHERE pv.product_id = ` + productIdExpr + `)`
+}
+
+// findProductVariants returns the options of the product in order and its variants sorted by SKU
+func (r *ProductRepositoryImpl) findProductVariants(ctx context.Context, productId int64) ([]entity.ProductOption, []entity.ProductVariant, error) {
+	options := []entity.ProductOption{}
+	rows, err := r.db.QueryContext(ctx, `SELECT name, option_values FROM product_options WHERE product_id = $1 ORDER BY position`, productId)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		option := entity.ProductOption{}
+		if err := rows.Scan(&option.Name, pq.Array(&option.Values)); err != nil {
+			return nil, nil, err
+		}
+		options = append(options, option)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	variants := []entity.ProductVariant{}
+	rows, err = r.db.QueryContext(ctx, `
+		SELECT id, product_id, sku, option_values, price, stock, image_url, created_at, updated_at
+		FROM product_variants
+		WHERE product_id = $1
+		ORDER BY sku
+	`, productId)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		variant := entity.ProductVariant{}
+		var price sql.NullInt64
+		var imageUrl sql.NullString
+		if err := rows.Scan(
+			&variant.ID,
+			&variant.ProductID,
+			&variant.SKU,
+			pq.Array(&variant.OptionValues),
+			&price,
+			&variant.Stock,
+			&imageUrl,
+			&variant.CreatedAt,
+			&variant.UpdatedAt,
+		); err != nil {
+			return nil, nil, err
+		}
+		if price.Valid {
+			p := int(price.Int64)
+			variant.Price = &p
+		}
+		variant.ImageURL = imageUrl.String
+		variants = append(variants, variant)
+	}
+
+	return options, variants, rows.Err()
+}
+
+// UpdateVariantStockByID sets the stock of one variant and recomputes the product stock from its variants
+func (r *ProductRepositoryImpl) UpdateVariantStockByID(ctx context.Context, productId int64, variantId int64, stock int, version int, userId int64) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	defer tx.Rollback()
+
+	if code, err := lockProductVersionTx(ctx, tx, productId, version); err != nil {
+		return code, err
+	}
+
+	updatedAt := time.Now().UnixMilli()
+	res, err := tx.ExecContext(ctx, `
+		UPDATE product_variants SET
+			stock=$1,
+			updated_at=$2
+		WHERE id = $3 AND product_id = $4
+	`, stock, updatedAt, variantId, productId)
+	if err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	row, err := res.RowsAffected()
+	if err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	if row == 0 {
+		return http.StatusNotFound, errors.Wrap(errorer.ErrNotFound, "variant not found")
+	}
+
+	if err := syncVariantStockTx(ctx, tx, productId); err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	if _, err := recordProductVersionTx(ctx, tx, productId, entity.ProductVersionActionStock, userId, updatedAt); err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	if err := tx.Commit(); err != nil {
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	return http.StatusOK, nil
+}
+
+// replaceProductOptionsTx overwrites the options of the product inside the given transaction
+func replaceProductOptionsTx(ctx context.Context, tx *sql.Tx, productId int64, options []entity.ProductOption) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM product_options WHERE product_id = $1`, productId); err != nil {
+		return err
+	}
+
+	for i, option := range options {
+		_, err := tx.ExecContext(ctx, `INSERT INTO product_options (product_id, position, name, option_values) VALUES ($1, $2, $3, $4)`,
+			productId, i, option.Name, pq.Array(option.Values))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// upsertProductVariantsTx matches the variants of the product by SKU: unknown SKUs are inserted, known ones updated
+// and missing ones deleted. The stock of known SKUs is only overwritten when keepStock is false
+func upsertProductVariantsTx(ctx context.Context, tx *sql.Tx, productId int64, variants []entity.ProductVariant, keepStock bool, updatedAt int64) error {
+	skus := make([]string, len(variants))
+	for i, v := range variants {
+		skus[i] = v.SKU
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM product_variants WHERE product_id = $1 AND NOT (sku = ANY($2))`, productId, pq.Array(skus)); err != nil {
+		return err
+	}
+
+	stockUpdate := "stock = EXCLUDED.stock,"
+	if keepStock {
+		stockUpdate = ""
+	}
+	query := `
+		INSERT INTO product_variants (product_id, sku, option_values, price, stock, image_url, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $7)
+		ON CONFLICT (product_id, sku) DO UPDATE SET
+			option_values = EXCLUDED.option_values,
+			price = EXCLUDED.price,
+			` + stockUpdate + `
+			image_url = EXCLUDED.image_url,
+			updated_at = EXCLUDED.updated_at
+	`
+	for _, v := range variants {
+		var price sql.NullInt64
+		if v.Price != nil {
+			price = sql.NullInt64{Int64: int64(*v.Price), Valid: true}
+		}
+		imageUrl := sql.NullString{String: v.ImageURL, Valid: v.ImageURL != ""}
+		_, err := tx.ExecContext(ctx, query, productId, v.SKU, pq.Array(v.OptionValues), price, v.Stock, imageUrl, updatedAt)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncVariantStockTx sets the stock of a product sold through variants to the sum of its variants stock
+func syncVariantStockTx(ctx context.Context, tx *sql.Tx, productId int64) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE products SET
+			stock = (SELECT COALESCE(SUM(stock), 0) FROM product_variants WHERE product_id = $1)
+		WHERE id = $1 AND `+productHasVariantsQuery("$1"), productId)
+	return err
+}
+
+// purchaseVariantTx locks the variant row and decrements its stock inside the given transaction.
+// The product row must already be locked by the caller
+func purchaseVariantTx(ctx context.Context, tx *sql.Tx, productId int64, variantId int64, amount int) (*entity.ProductVariant, int, error) {
+	variant := entity.ProductVariant{}
+	var price sql.NullInt64
+	var imageUrl sql.NullString
+	err := tx.QueryRowContext(ctx, `
+		SELECT id, product_id, sku, price, stock, image_url
+		FROM product_variants
+		WHERE id = $1 AND product_id = $2
+		FOR UPDATE
+	`, variantId, productId).Scan(&variant.ID, &variant.ProductID, &variant.SKU, &price, &variant.Stock, &imageUrl)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, http.StatusNotFound, errors.Wrap(errorer.ErrNotFound, "variant not found")
+		}
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	if price.Valid {
+		p := int(price.Int64)
+		variant.Price = &p
+	}
+	variant.ImageURL = imageUrl.String
+
+	if variant.Stock < amount {
+		return nil, http.StatusBadRequest, errors.Wrap(errorer.ErrInputRequest(errors.New("insufficient stock")), errorer.ErrInputRequest(errors.New("insufficient stock")).Error())
+	}
+	variant.Stock -= amount
+
+	if _, err := tx.ExecContext(ctx, `UPDATE product_variants SET stock = $1 WHERE id = $2`, variant.Stock, variant.ID); err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	return &variant, http.StatusOK, nil
+}
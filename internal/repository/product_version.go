@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"ecomm/internal/helper/common"
+	"ecomm/internal/helper/errorer"
+	"ecomm/internal/model/entity"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// FindVersions returns the history of a product owned by the user, latest version first.
+// Deleted products keep their history
+func (r *ProductRepositoryImpl) FindVersions(ctx context.Context, filter entity.GetProductVersionFilter) ([]entity.ProductVersion, *common.Meta, int, error) {
+	var ownerId int64
+	err := r.db.QueryRowContext(ctx, `SELECT user_id FROM products WHERE id = $1`, filter.ProductID).Scan(&ownerId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil, http.StatusNotFound, errors.Wrap(errorer.ErrNotFound, "product not found")
+		}
+		return nil, nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	if ownerId != filter.UserID {
+		return nil, nil, http.StatusForbidden, errors.Wrap(errorer.ErrForbidden, errorer.ErrForbidden.Error())
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, product_id, version, action, snapshot, user_id, created_at
+		FROM product_versions
+		WHERE product_id = $1
+		ORDER BY version DESC
+		LIMIT $2 OFFSET $3
+	`, filter.ProductID, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	defer rows.Close()
+
+	versions := []entity.ProductVersion{}
+	for rows.Next() {
+		version := entity.ProductVersion{}
+		if err := rows.Scan(&version.ID, &version.ProductID, &version.Version, &version.Action, &version.Snapshot, &version.UserID, &version.CreatedAt); err != nil {
+			return nil, nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+		}
+		versions = append(versions, version)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	var totalCount int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM product_versions WHERE product_id = $1`, filter.ProductID).Scan(&totalCount); err != nil {
+		return nil, nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	return versions, &common.Meta{
+		Total:  totalCount,
+		Limit:  filter.Limit,
+		Offset: filter.Offset,
+	}, http.StatusOK, nil
+}
+
+// recordProductVersionTx bumps the product version and stores a snapshot of the product as it is
+// inside the given transaction, so it must run after every other change of the same action
+func recordProductVersionTx(ctx context.Context, tx *sql.Tx, productId int64, action string, userId int64, createdAt int64) (int, error) {
+	var version int
+	if err := tx.QueryRowContext(ctx, `UPDATE products SET version = version + 1 WHERE id = $1 RETURNING version`, productId).Scan(&version); err != nil {
+		return 0, err
+	}
+
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO product_versions (product_id, version, action, snapshot, user_id, created_at)
+		VALUES ($1, $2, $3, product_snapshot($1), $4, $5)
+	`, productId, version, action, userId, createdAt)
+	return version, err
+}
+
+// lockProductVersionTx locks a product that is not deleted and checks that it is still at the version the
+// caller has read, so changes based on an outdated read are refused with 412 instead of overwriting newer ones
+func lockProductVersionTx(ctx context.Context, tx *sql.Tx, productId int64, version int) (int, error) {
+	var current int
+	err := tx.QueryRowContext(ctx, `SELECT version FROM products WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, productId).Scan(&current)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return http.StatusNotFound, errors.Wrap(errorer.ErrNotFound, "product not found")
+		}
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	if current != version {
+		return http.StatusPreconditionFailed, errors.Wrap(errorer.ErrPreconditionFailed, "product has been modified since it was read")
+	}
+	return http.StatusOK, nil
+}
@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"ecomm/internal/helper/errorer"
+	"ecomm/internal/model/entity"
+	"fmt"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+// reservedStockQuery sums the quantity held by active, unexpired reservations of the product
+// referenced by the given SQL expression
+func reservedStockQuery(productIdExpr string) string {
+	return `COALESCE((SELECT SUM(r.quantity) FROM reservations as r
+		WHERE r.product_id = ` + productIdExpr + `
+		AND r.status = 'active'
+		AND r.expires_at > (EXTRACT(EPOCH FROM NOW()) * 1000)), 0)`
+}
+
+type ReservationRepository interface {
+	Create(ctx context.Context, ent entity.Reservation, maxQuantity int) (*entity.Reservation, int, error)
+	ReleaseExpired(ctx context.Context, now int64) (int64, int, error)
+}
+
+func NewReservationRepository(logger zerolog.Logger, db *sql.DB) ReservationRepository {
+	return &ReservationRepositoryImpl{
+		logger: logger,
+		db:     db,
+	}
+}
+
+type ReservationRepositoryImpl struct {
+	logger zerolog.Logger
+	db     *sql.DB
+}
+
+// Create holds stock for the buyer. The units the buyer already holds on the product count against maxQuantity,
+// 0 meaning unlimited, and together with the purchased ones against the product purchase limit per buyer
+func (r *ReservationRepositoryImpl) Create(ctx context.Context, ent entity.Reservation, maxQuantity int) (*entity.Reservation, int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	defer tx.Rollback()
+
+	var available, purchaseLimit int
+	err = tx.QueryRowContext(ctx, `SELECT stock - `+reservedStockQuery("products.id")+`, purchase_limit_per_buyer FROM products WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, ent.ProductID).
+		Scan(&available, &purchaseLimit)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, http.StatusNotFound, errors.Wrap(errorer.ErrNotFound, errorer.ErrNotFound.Error())
+		}
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	if available < ent.Quantity {
+		return nil, http.StatusBadRequest, errors.Wrap(errorer.ErrInputRequest(errors.New("insufficient stock")), errorer.ErrInputRequest(errors.New("insufficient stock")).Error())
+	}
+
+	var held int
+	err = tx.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(quantity), 0) FROM reservations
+		WHERE user_id = $1 AND product_id = $2 AND status = $3 AND expires_at > $4
+	`, ent.UserID, ent.ProductID, entity.ReservationStatusActive, ent.CreatedAt).Scan(&held)
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	if maxQuantity > 0 && held+ent.Quantity > maxQuantity {
+		message := fmt.Sprintf("cannot hold more than %d units of a product", maxQuantity)
+		return nil, http.StatusBadRequest, errors.Wrap(errorer.ErrRejected(entity.PurchaseRejectedQuantityExceeded, message), message)
+	}
+	if purchaseLimit > 0 {
+		purchased, err := sumQuantityByBuyer(ctx, tx, ent.UserID, ent.ProductID)
+		if err != nil {
+			return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+		}
+		if purchased+held+ent.Quantity > purchaseLimit {
+			message := fmt.Sprintf("each buyer can purchase at most %d units of this product", purchaseLimit)
+			return nil, http.StatusBadRequest, errors.Wrap(errorer.ErrRejected(entity.PurchaseRejectedBuyerLimit, message), message)
+		}
+	}
+
+	ent.Status = entity.ReservationStatusActive
+	query := `
+		Insert into reservations
+		(
+			user_id,
+			product_id,
+			quantity,
+			status,
+			expires_at,
+			created_at,
+			updated_at
+		)
+		Values($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id;
+	`
+	err = tx.QueryRowContext(ctx, query, ent.UserID, ent.ProductID, ent.Quantity, ent.Status,
+		ent.ExpiresAt, ent.CreatedAt, ent.UpdatedAt).Scan(&ent.ID)
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	return &ent, http.StatusOK, nil
+}
+
+// ReleaseExpired releases every active reservation that expired before now and returns how many were released
+func (r *ReservationRepositoryImpl) ReleaseExpired(ctx context.Context, now int64) (int64, int, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE reservations SET
+			status=$1,
+			updated_at=$2
+		WHERE status = $3 AND expires_at <= $2
+	`, entity.ReservationStatusReleased, now, entity.ReservationStatusActive)
+	if err != nil {
+		return 0, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	row, err := res.RowsAffected()
+	if err != nil {
+		return 0, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	return row, http.StatusOK, nil
+}
+
+// consumeReservationTx marks an active reservation of the buyer as consumed inside the given transaction
+// so its quantity is no longer held when the purchase decrements the stock
+func consumeReservationTx(ctx context.Context, tx *sql.Tx, id int64, userId int64, productId int64, quantity int, updatedAt int64) (int, error) {
+	var reserved int
+	err := tx.QueryRowContext(ctx, `
+		UPDATE reservations SET
+			status=$1,
+			updated_at=$2
+		WHERE id = $3 AND user_id = $4 AND product_id = $5 AND status = $6
+			AND expires_at > (EXTRACT(EPOCH FROM NOW()) * 1000)
+		RETURNING quantity
+	`, entity.ReservationStatusConsumed, updatedAt, id, userId, productId, entity.ReservationStatusActive).Scan(&reserved)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return http.StatusBadRequest, errors.Wrap(errorer.ErrInputRequest(errors.New("reservation not found or expired")), errorer.ErrInputRequest(errors.New("reservation not found or expired")).Error())
+		}
+		return http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	if reserved != quantity {
+		return http.StatusBadRequest, errors.Wrap(errorer.ErrInputRequest(errors.New("quantity does not match reservation")), errorer.ErrInputRequest(errors.New("quantity does not match reservation")).Error())
+	}
+
+	return http.StatusOK, nil
+}
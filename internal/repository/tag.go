@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"ecomm/internal/helper/errorer"
+	"ecomm/internal/model/entity"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+type TagRepository interface {
+	FindAll(ctx context.Context, filter entity.GetAllTagFilter) ([]entity.Tag, int, error)
+}
+
+func NewTagRepository(logger zerolog.Logger, db *sql.DB) TagRepository {
+	return &TagRepositoryImpl{
+		logger: logger,
+		db:     db,
+	}
+}
+
+type TagRepositoryImpl struct {
+	logger zerolog.Logger
+	db     *sql.DB
+}
+
+// likeEscaper escapes the LIKE wildcards of user input
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// FindAll returns the most used tags starting with the prefix
+func (r *TagRepositoryImpl) FindAll(ctx context.Context, filter entity.GetAllTagFilter) ([]entity.Tag, int, error) {
+	tags := []entity.Tag{}
+	query := `
+		SELECT pt.tag, COUNT(*)
+		FROM product_tags as pt
+		JOIN products as p ON pt.product_id = p.id AND p.deleted_at IS NULL
+		WHERE pt.tag LIKE $1
+		GROUP BY pt.tag
+		ORDER BY COUNT(*) DESC, pt.tag
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, likeEscaper.Replace(filter.Prefix)+"%", filter.Limit)
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		tag := entity.Tag{}
+		if err := rows.Scan(&tag.Name, &tag.Count); err != nil {
+			return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+		}
+		tags = append(tags, tag)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(errorer.ErrInternalDatabase, err.Error())
+	}
+
+	return tags, http.StatusOK, nil
+}
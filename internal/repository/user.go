@@ -50,8 +50,8 @@ func (r *UserRepositoryImpl) Register(ctx context.Context, user entity.User) (*e
 func (r *UserRepositoryImpl) FindByUsername(ctx context.Context, username string) (*entity.User, int, error) {
 	var user entity.User
 
-	row := r.db.QueryRowContext(ctx, "SELECT id, username, password, name, created_at, updated_at FROM users WHERE username = $1", username)
-	err := row.Scan(&user.ID, &user.Username, &user.Password, &user.Name, &user.CreatedAt, &user.UpdatedAt)
+	row := r.db.QueryRowContext(ctx, "SELECT id, username, password, name, is_suspended, created_at, updated_at FROM users WHERE username = $1", username)
+	err := row.Scan(&user.ID, &user.Username, &user.Password, &user.Name, &user.IsSuspended, &user.CreatedAt, &user.UpdatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, http.StatusNotFound, errors.Wrap(errorer.ErrNotFound, errorer.ErrNotFound.Error())
@@ -64,8 +64,8 @@ func (r *UserRepositoryImpl) FindByUsername(ctx context.Context, username string
 func (r *UserRepositoryImpl) FindByID(ctx context.Context, id int64) (*entity.User, int, error) {
 	var user entity.User
 
-	row := r.db.QueryRowContext(ctx, "SELECT id, username, password, name, created_at, updated_at FROM users WHERE id = $1", id)
-	err := row.Scan(&user.ID, &user.Username, &user.Password, &user.Name, &user.CreatedAt, &user.UpdatedAt)
+	row := r.db.QueryRowContext(ctx, "SELECT id, username, password, name, is_suspended, is_admin, created_at, updated_at FROM users WHERE id = $1", id)
+	err := row.Scan(&user.ID, &user.Username, &user.Password, &user.Name, &user.IsSuspended, &user.IsAdmin, &user.CreatedAt, &user.UpdatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, http.StatusNotFound, errors.Wrap(errorer.ErrNotFound, errorer.ErrNotFound.Error())
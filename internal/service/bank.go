@@ -50,14 +50,15 @@ func (s *service) GetBankByID(ctx context.Context, id int64) (*response.Bank, in
 		UserID:        ent.UserID,
 		CreatedAt:     ent.CreatedAt,
 		UpdatedAt:     ent.UpdatedAt,
+		Version:       ent.Version,
 	}, code, nil
 }
-func (s *service) DeleteBankByID(ctx context.Context, id int64) (int, error) {
+func (s *service) DeleteBankByID(ctx context.Context, id int64, version int) (int, error) {
 	if id == 0 {
 		return http.StatusBadRequest, errors.Wrap(errors.New("invalid bank id"), "invalid bank id")
 	}
 
-	code, err := s.bankRepo.DeleteByID(ctx, id)
+	code, err := s.bankRepo.DeleteByID(ctx, id, version)
 	if err != nil {
 		return code, err
 	}
@@ -73,6 +74,7 @@ func (s *service) UpdateBankByID(ctx context.Context, req request.UpdateBank) (i
 		Name:          req.Name,
 		AccountName:   req.AccountName,
 		AccountNumber: req.AccountNumber,
+		Version:       req.Version,
 		UpdatedAt:     time.Now().UnixMilli(),
 	})
 
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"ecomm/internal/helper/errorer"
+	"ecomm/internal/helper/validator"
+	"ecomm/internal/model/entity"
+	"ecomm/internal/model/request"
+	"ecomm/internal/model/response"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func (s *service) GetCart(ctx context.Context, userId int64) (*response.Cart, int, error) {
+	items, code, err := s.cartRepo.FindAll(ctx, userId)
+	if err != nil {
+		return nil, code, err
+	}
+
+	cart := response.Cart{Items: make([]response.CartItem, len(items))}
+	for i, v := range items {
+		cart.Items[i] = response.CartItem{
+			ProductID: strconv.Itoa(int(v.ProductID)),
+			Name:      v.Product.Name,
+			Price:     v.Product.Price,
+			ImageURL:  v.Product.ImageURL,
+			Stock:     v.Product.Stock,
+			SellerID:  v.Product.UserID,
+			Quantity:  v.Quantity,
+			Subtotal:  v.Product.Price * v.Quantity,
+			CreatedAt: v.CreatedAt,
+			UpdatedAt: v.UpdatedAt,
+		}
+		cart.TotalQuantity += v.Quantity
+		cart.TotalPrice += cart.Items[i].Subtotal
+	}
+
+	return &cart, http.StatusOK, nil
+}
+
+func (s *service) AddCartItem(ctx context.Context, req request.AddCartItem) (int, error) {
+	if err := validator.ValidateStruct(&req); err != nil {
+		return http.StatusBadRequest, errors.Wrap(errorer.ErrInputRequest(err), errorer.ErrInputRequest(err).Error())
+	}
+
+	productId, _ := strconv.Atoi(req.ProductId)
+	prd, code, err := s.productRepo.FindByID(ctx, int64(productId))
+	if err != nil {
+		return code, err
+	}
+	// cart items have no variant, products sold through variants are bought one at a time
+	if prd.HasVariants {
+		return rejectPurchase(PurchaseRejectedVariantRequired, "products with variants cannot be added to the cart")
+	}
+
+	return s.cartRepo.Add(ctx, entity.CartItem{
+		UserID:    req.UserID,
+		ProductID: int64(productId),
+		Quantity:  req.Quantity,
+		CreatedAt: time.Now().UnixMilli(),
+		UpdatedAt: time.Now().UnixMilli(),
+	})
+}
+
+func (s *service) UpdateCartItem(ctx context.Context, req request.UpdateCartItem) (int, error) {
+	if err := validator.ValidateStruct(&req); err != nil {
+		return http.StatusBadRequest, errors.Wrap(errorer.ErrInputRequest(err), errorer.ErrInputRequest(err).Error())
+	}
+
+	return s.cartRepo.UpdateQuantity(ctx, entity.CartItem{
+		UserID:    req.UserID,
+		ProductID: req.ProductId,
+		Quantity:  req.Quantity,
+		UpdatedAt: time.Now().UnixMilli(),
+	})
+}
+
+func (s *service) DeleteCartItem(ctx context.Context, userId int64, productId int64) (int, error) {
+	if productId == 0 {
+		return http.StatusBadRequest, errors.Wrap(errors.New("invalid product id"), "invalid product id")
+	}
+
+	return s.cartRepo.Delete(ctx, userId, productId)
+}
+
+// Checkout splits the cart into one order per seller, each paid to one of that seller's bank accounts
+func (s *service) Checkout(ctx context.Context, req request.Checkout) ([]response.Order, int, error) {
+	if err := validator.ValidateStruct(&req); err != nil {
+		return nil, http.StatusBadRequest, errors.Wrap(errorer.ErrInputRequest(err), errorer.ErrInputRequest(err).Error())
+	}
+
+	items, code, err := s.cartRepo.FindAll(ctx, req.UserID)
+	if err != nil {
+		return nil, code, err
+	}
+	if len(items) == 0 {
+		return nil, http.StatusBadRequest, errors.Wrap(errorer.ErrInputRequest(errors.New("cart is empty")), "cart is empty")
+	}
+
+	payments := map[int64]request.CheckoutPayment{}
+	for _, v := range req.Payments {
+		sellerId, _ := strconv.Atoi(v.SellerId)
+		if _, ok := payments[int64(sellerId)]; ok {
+			err := fmt.Errorf("duplicate payment for seller %d", sellerId)
+			return nil, http.StatusBadRequest, errors.Wrap(errorer.ErrInputRequest(err), errorer.ErrInputRequest(err).Error())
+		}
+		payments[int64(sellerId)] = v
+	}
+
+	itemsBySeller := map[int64][]entity.CartItem{}
+	for _, v := range items {
+		if code, err := s.checkPurchase(ctx, Purchase{BuyerID: req.UserID, Product: &v.Product, Quantity: v.Quantity}); err != nil {
+			return nil, code, err
+		}
+		itemsBySeller[v.Product.UserID] = append(itemsBySeller[v.Product.UserID], v)
+	}
+
+	sellerIds := make([]int64, 0, len(itemsBySeller))
+	for id := range itemsBySeller {
+		sellerIds = append(sellerIds, id)
+	}
+	sort.Slice(sellerIds, func(i, j int) bool { return sellerIds[i] < sellerIds[j] })
+
+	now := time.Now().UnixMilli()
+	orders := make([]entity.Order, len(sellerIds))
+	for i, sellerId := range sellerIds {
+		payment, ok := payments[sellerId]
+		if !ok {
+			err := fmt.Errorf("missing payment for seller %d", sellerId)
+			return nil, http.StatusBadRequest, errors.Wrap(errorer.ErrInputRequest(err), errorer.ErrInputRequest(err).Error())
+		}
+		delete(payments, sellerId)
+
+		bankId, _ := strconv.Atoi(payment.BankAccountId)
+		bank, _, err := s.bankRepo.FindByID(ctx, int64(bankId))
+		if err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+		if bank.UserID != sellerId {
+			return nil, http.StatusBadRequest, errors.Wrap(errorer.ErrInputRequest(errors.New("bank account not owned by seller")), "bank account not owned by seller")
+		}
+
+		orders[i] = entity.Order{
+			SellerID:             sellerId,
+			BankID:               bank.ID,
+			PaymentProofImageURL: payment.PaymentProofImageUrl,
+			Items:                itemsBySeller[sellerId],
+			CreatedAt:            now,
+			UpdatedAt:            now,
+		}
+	}
+
+	if len(payments) > 0 {
+		err := errors.New("payment given for a seller that is not in the cart")
+		return nil, http.StatusBadRequest, errors.Wrap(errorer.ErrInputRequest(err), errorer.ErrInputRequest(err).Error())
+	}
+
+	orders, code, err = s.orderRepo.Checkout(ctx, req.UserID, orders)
+	if err != nil {
+		return nil, code, err
+	}
+
+	list := make([]response.Order, len(orders))
+	for i, v := range orders {
+		list[i] = response.Order{
+			ID:                   strconv.Itoa(int(v.ID)),
+			SellerID:             v.SellerID,
+			BankAccountID:        strconv.Itoa(int(v.BankID)),
+			PaymentProofImageUrl: v.PaymentProofImageURL,
+			TotalPrice:           v.TotalPrice,
+			Payments:             make([]response.Payment, len(v.Payments)),
+			UserID:               v.UserID,
+			CreatedAt:            v.CreatedAt,
+			UpdatedAt:            v.UpdatedAt,
+		}
+		for j, p := range v.Payments {
+			list[i].Payments[j] = paymentToResponse(p)
+		}
+	}
+
+	return list, code, nil
+}
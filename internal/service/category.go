@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"ecomm/internal/helper/errorer"
+	"ecomm/internal/helper/validator"
+	"ecomm/internal/model/entity"
+	"ecomm/internal/model/request"
+	"ecomm/internal/model/response"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// GetCategories returns the category tree, top level categories first
+func (s *service) GetCategories(ctx context.Context) ([]response.Category, int, error) {
+	ent, code, err := s.categoryRepo.FindAll(ctx)
+	if err != nil {
+		return nil, code, err
+	}
+
+	children := map[int64][]entity.Category{}
+	for _, v := range ent {
+		children[v.ParentID] = append(children[v.ParentID], v)
+	}
+
+	var build func(parentId int64) []response.Category
+	build = func(parentId int64) []response.Category {
+		list := make([]response.Category, len(children[parentId]))
+		for i, v := range children[parentId] {
+			list[i] = categoryToResponse(v)
+			list[i].Children = build(v.ID)
+		}
+		return list
+	}
+
+	return build(0), http.StatusOK, nil
+}
+
+func (s *service) CreateCategory(ctx context.Context, req request.Category) (*response.Category, int, error) {
+	if err := validator.ValidateStruct(&req); err != nil {
+		return nil, http.StatusBadRequest, errors.Wrap(errorer.ErrInputRequest(err), errorer.ErrInputRequest(err).Error())
+	}
+
+	parentId, _ := strconv.Atoi(req.ParentId)
+	if parentId != 0 {
+		if _, code, err := s.categoryRepo.FindByID(ctx, int64(parentId)); err != nil {
+			if code == http.StatusNotFound {
+				return nil, http.StatusBadRequest, errors.Wrap(errorer.ErrInputRequest(errors.New("parent category not found")), "parent category not found")
+			}
+			return nil, code, err
+		}
+	}
+
+	ent, code, err := s.categoryRepo.Create(ctx, entity.Category{
+		Name:      req.Name,
+		ParentID:  int64(parentId),
+		CreatedAt: time.Now().UnixMilli(),
+		UpdatedAt: time.Now().UnixMilli(),
+	})
+	if err != nil {
+		return nil, code, err
+	}
+
+	res := categoryToResponse(*ent)
+	return &res, code, nil
+}
+
+func (s *service) UpdateCategoryByID(ctx context.Context, req request.UpdateCategory) (int, error) {
+	if err := validator.ValidateStruct(&req); err != nil {
+		return http.StatusBadRequest, errors.Wrap(errorer.ErrInputRequest(err), errorer.ErrInputRequest(err).Error())
+	}
+
+	parentId, _ := strconv.Atoi(req.ParentId)
+	if parentId != 0 {
+		if _, code, err := s.categoryRepo.FindByID(ctx, int64(parentId)); err != nil {
+			if code == http.StatusNotFound {
+				return http.StatusBadRequest, errors.Wrap(errorer.ErrInputRequest(errors.New("parent category not found")), "parent category not found")
+			}
+			return code, err
+		}
+
+		// moving a category under itself or one of its descendants would cut it off the tree
+		cyclic, code, err := s.categoryRepo.IsDescendant(ctx, int64(parentId), req.ID)
+		if err != nil {
+			return code, err
+		}
+		if cyclic {
+			return http.StatusBadRequest, errors.Wrap(errorer.ErrInputRequest(errors.New("category cannot be moved under itself")), "category cannot be moved under itself")
+		}
+	}
+
+	return s.categoryRepo.UpdateByID(ctx, entity.Category{
+		ID:        req.ID,
+		Name:      req.Name,
+		ParentID:  int64(parentId),
+		UpdatedAt: time.Now().UnixMilli(),
+	})
+}
+
+func (s *service) DeleteCategoryByID(ctx context.Context, id int64) (int, error) {
+	if id == 0 {
+		return http.StatusBadRequest, errors.Wrap(errors.New("invalid category id"), "invalid category id")
+	}
+
+	return s.categoryRepo.DeleteByID(ctx, id)
+}
+
+// validateCategory checks that the category a product is filed under exists
+func (s *service) validateCategory(ctx context.Context, categoryId int64) (int, error) {
+	_, code, err := s.categoryRepo.FindByID(ctx, categoryId)
+	if err != nil {
+		if code == http.StatusNotFound {
+			return http.StatusBadRequest, errors.Wrap(errorer.ErrInputRequest(errors.New("category not found")), "category not found")
+		}
+		return code, err
+	}
+	return http.StatusOK, nil
+}
+
+func categoryToResponse(ent entity.Category) response.Category {
+	res := response.Category{
+		ID:        strconv.Itoa(int(ent.ID)),
+		Name:      ent.Name,
+		Children:  []response.Category{},
+		CreatedAt: ent.CreatedAt,
+		UpdatedAt: ent.UpdatedAt,
+	}
+	if ent.ParentID != 0 {
+		res.ParentID = strconv.Itoa(int(ent.ParentID))
+	}
+	return res
+}
@@ -0,0 +1,207 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"ecomm/internal/helper/errorer"
+	"ecomm/internal/helper/validator"
+	"ecomm/internal/model/entity"
+	"ecomm/internal/model/request"
+	"ecomm/internal/model/response"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	ExportFormatCSV    = "csv"
+	ExportFormatNDJSON = "ndjson"
+)
+
+var productExportHeader = []string{
+	"productId",
+	"name",
+	"description",
+	"price",
+	"imageUrl",
+	"stock",
+	"condition",
+	"tags",
+	"categoryId",
+	"isPurchasable",
+	"purchaseLimitPerBuyer",
+	"purchaseCount",
+	"createdAt",
+	"updatedAt",
+}
+
+var salesExportHeader = []string{
+	"paymentId",
+	"orderId",
+	"productId",
+	"productName",
+	"productVersion",
+	"variantId",
+	"sku",
+	"price",
+	"quantity",
+	"total",
+	"status",
+	"buyerId",
+	"bankAccountId",
+	"bankName",
+	"createdAt",
+	"updatedAt",
+}
+
+// exportEncoder writes one record per row, as a CSV line or a JSON document on its own line.
+// Output is buffered so nothing reaches w before the first few rows are encoded
+type exportEncoder struct {
+	csv  *csv.Writer
+	buf  *bufio.Writer
+	json *json.Encoder
+}
+
+func newExportEncoder(format string, w io.Writer, header []string) (*exportEncoder, error) {
+	if format == ExportFormatCSV {
+		enc := &exportEncoder{csv: csv.NewWriter(w)}
+		return enc, enc.csv.Write(header)
+	}
+
+	buf := bufio.NewWriter(w)
+	return &exportEncoder{buf: buf, json: json.NewEncoder(buf)}, nil
+}
+
+func (e *exportEncoder) encode(record []string, doc interface{}) error {
+	if e.csv != nil {
+		return e.csv.Write(record)
+	}
+	return e.json.Encode(doc)
+}
+
+func (e *exportEncoder) flush() error {
+	if e.csv != nil {
+		e.csv.Flush()
+		return e.csv.Error()
+	}
+	return e.buf.Flush()
+}
+
+// ExportProducts streams every product of the seller to w, row by row from the database cursor
+func (s *service) ExportProducts(ctx context.Context, req request.Export, w io.Writer) (int, error) {
+	if err := validator.ValidateStruct(&req); err != nil {
+		return http.StatusBadRequest, errors.Wrap(errorer.ErrInputRequest(err), errorer.ErrInputRequest(err).Error())
+	}
+
+	enc, err := newExportEncoder(req.Format, w, productExportHeader)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	code, err := s.productRepo.StreamByUserID(ctx, req.UserID, func(prd entity.Product) error {
+		return enc.encode(productExportRecord(prd), response.Product{
+			ID:            strconv.Itoa(int(prd.ID)),
+			Name:          prd.Name,
+			Description:   prd.Description,
+			Price:         prd.Price,
+			ImageURL:      prd.ImageURL,
+			Stock:         prd.Stock,
+			UserID:        prd.UserID,
+			IsPurchasable: prd.IsPurchasable,
+			Condition:     prd.Condition,
+			Tags:          prd.Tags,
+			CategoryID:    categoryIDToResponse(prd.CategoryID),
+			PurchaseCount: prd.PurchaseCount,
+			PurchaseLimit: prd.PurchaseLimit,
+			CreatedAt:     prd.CreatedAt,
+			UpdatedAt:     prd.UpdatedAt,
+		})
+	})
+	if err != nil {
+		return code, err
+	}
+
+	if err := enc.flush(); err != nil {
+		return http.StatusInternalServerError, err
+	}
+	return http.StatusOK, nil
+}
+
+// ExportSales streams every payment received by the seller to w, row by row from the database cursor
+func (s *service) ExportSales(ctx context.Context, req request.Export, w io.Writer) (int, error) {
+	if err := validator.ValidateStruct(&req); err != nil {
+		return http.StatusBadRequest, errors.Wrap(errorer.ErrInputRequest(err), errorer.ErrInputRequest(err).Error())
+	}
+
+	enc, err := newExportEncoder(req.Format, w, salesExportHeader)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	code, err := s.paymentRepo.StreamBySellerID(ctx, req.UserID, func(payment entity.Payment) error {
+		return enc.encode(salesExportRecord(payment), paymentToResponse(payment))
+	})
+	if err != nil {
+		return code, err
+	}
+
+	if err := enc.flush(); err != nil {
+		return http.StatusInternalServerError, err
+	}
+	return http.StatusOK, nil
+}
+
+func productExportRecord(prd entity.Product) []string {
+	return []string{
+		strconv.Itoa(int(prd.ID)),
+		prd.Name,
+		prd.Description,
+		strconv.Itoa(prd.Price),
+		prd.ImageURL,
+		strconv.Itoa(prd.Stock),
+		prd.Condition,
+		strings.Join(prd.Tags, importTagSeparator),
+		categoryIDToResponse(prd.CategoryID),
+		strconv.FormatBool(prd.IsPurchasable),
+		strconv.Itoa(prd.PurchaseLimit),
+		strconv.Itoa(prd.PurchaseCount),
+		strconv.FormatInt(prd.CreatedAt, 10),
+		strconv.FormatInt(prd.UpdatedAt, 10),
+	}
+}
+
+func salesExportRecord(payment entity.Payment) []string {
+	record := []string{
+		strconv.Itoa(int(payment.ID)),
+		"",
+		strconv.Itoa(int(payment.ProductID)),
+		payment.ProductName,
+		"",
+		"",
+		payment.VariantSKU,
+		strconv.Itoa(payment.ProductPrice),
+		strconv.Itoa(payment.Quantity),
+		strconv.Itoa(payment.ProductPrice * payment.Quantity),
+		payment.Status,
+		strconv.FormatInt(payment.UserID, 10),
+		strconv.Itoa(int(payment.BankID)),
+		payment.Bank.Name,
+		strconv.FormatInt(payment.CreatedAt, 10),
+		strconv.FormatInt(payment.UpdatedAt, 10),
+	}
+	if payment.OrderID != 0 {
+		record[1] = strconv.Itoa(int(payment.OrderID))
+	}
+	if payment.ProductVersion != 0 {
+		record[4] = strconv.Itoa(payment.ProductVersion)
+	}
+	if payment.VariantID != 0 {
+		record[5] = strconv.Itoa(int(payment.VariantID))
+	}
+	return record
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"ecomm/internal/helper/errorer"
+	"ecomm/internal/helper/validator"
+	"ecomm/internal/model/entity"
+	"ecomm/internal/model/request"
+	"ecomm/internal/model/response"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// AcquireIdempotencyKey claims the key for the request. It returns the record of an earlier request
+// that used the same key, or nil when the request should be executed
+func (s *service) AcquireIdempotencyKey(ctx context.Context, req request.IdempotencyKey) (*response.IdempotencyKey, int, error) {
+	if err := validator.ValidateStruct(&req); err != nil {
+		return nil, http.StatusBadRequest, errors.Wrap(errorer.ErrInputRequest(err), errorer.ErrInputRequest(err).Error())
+	}
+
+	now := time.Now()
+	existing, code, err := s.idempotencyRepo.Acquire(ctx, entity.IdempotencyKey{
+		UserID:      req.UserID,
+		Key:         req.Key,
+		RequestHash: req.RequestHash,
+		ExpiresAt:   now.Add(s.cfg.IdempotencyTTL).UnixMilli(),
+		CreatedAt:   now.UnixMilli(),
+		UpdatedAt:   now.UnixMilli(),
+	})
+	if err != nil {
+		return nil, code, err
+	}
+
+	if existing == nil {
+		return nil, code, nil
+	}
+
+	if existing.RequestHash != req.RequestHash {
+		return nil, http.StatusUnprocessableEntity, errors.Wrap(errorer.ErrConflict, "idempotency key was already used for a different request")
+	}
+
+	if !existing.Completed {
+		return nil, http.StatusConflict, errors.Wrap(errorer.ErrConflict, "a request with this idempotency key is still in progress")
+	}
+
+	return &response.IdempotencyKey{
+		Key:                        existing.Key,
+		RequestHash:                existing.RequestHash,
+		ResponseCode:               existing.ResponseCode,
+		ResponseBody:               existing.ResponseBody,
+		Completed:                  existing.Completed,
+		ResponseContentType:        existing.ResponseContentType,
+		ResponseContentDisposition: existing.ResponseContentDisposition,
+	}, code, nil
+}
+
+func (s *service) SaveIdempotencyResponse(ctx context.Context, req request.IdempotencyKey) (int, error) {
+	return s.idempotencyRepo.SaveResponse(ctx, entity.IdempotencyKey{
+		UserID:                     req.UserID,
+		Key:                        req.Key,
+		ResponseCode:               req.ResponseCode,
+		ResponseBody:               req.ResponseBody,
+		UpdatedAt:                  time.Now().UnixMilli(),
+		ResponseContentType:        req.ResponseContentType,
+		ResponseContentDisposition: req.ResponseContentDisposition,
+	})
+}
+
+// ReleaseIdempotencyKey forgets the key so the client can retry a request that failed
+func (s *service) ReleaseIdempotencyKey(ctx context.Context, userId int64, key string) (int, error) {
+	return s.idempotencyRepo.Delete(ctx, userId, key)
+}
+
+func (s *service) DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, int, error) {
+	return s.idempotencyRepo.DeleteExpired(ctx, time.Now().UnixMilli())
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"ecomm/internal/helper/common"
+	"ecomm/internal/model/entity"
+	"ecomm/internal/model/request"
+	"ecomm/internal/model/response"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+func (s *service) GetNotifications(ctx context.Context, req request.GetNotifications) ([]response.Notification, *common.Meta, int, error) {
+	notifications, meta, code, err := s.notificationRepo.FindAll(ctx, entity.GetAllNotificationFilter{
+		UserID:     req.UserID,
+		UnreadOnly: req.UnreadOnly,
+		Limit:      req.Limit,
+		Offset:     req.Offset,
+	})
+	if err != nil {
+		return nil, nil, code, err
+	}
+
+	res := make([]response.Notification, len(notifications))
+	for i, n := range notifications {
+		res[i] = response.Notification{
+			ID:        strconv.Itoa(int(n.ID)),
+			Type:      n.Type,
+			Message:   n.Message,
+			Read:      n.ReadAt != 0,
+			CreatedAt: n.CreatedAt,
+		}
+		if n.ProductID != 0 {
+			res[i].ProductID = strconv.Itoa(int(n.ProductID))
+		}
+	}
+
+	return res, meta, http.StatusOK, nil
+}
+
+func (s *service) MarkNotificationRead(ctx context.Context, id int64, userId int64) (int, error) {
+	return s.notificationRepo.MarkRead(ctx, id, userId, time.Now().UnixMilli())
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"ecomm/internal/helper/common"
+	"ecomm/internal/helper/errorer"
+	"ecomm/internal/helper/validator"
+	"ecomm/internal/model/entity"
+	"ecomm/internal/model/request"
+	"ecomm/internal/model/response"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// paymentTransitions lists the statuses a payment may move to from its current status
+var paymentTransitions = map[string][]string{
+	entity.PaymentStatusPendingVerification: {entity.PaymentStatusPaid, entity.PaymentStatusRejected, entity.PaymentStatusCancelled},
+	entity.PaymentStatusPaid:                {entity.PaymentStatusShipped},
+	entity.PaymentStatusShipped:             {entity.PaymentStatusCompleted},
+}
+
+// sellerPaymentStatuses are the statuses only the seller may set, the rest belong to the buyer
+var sellerPaymentStatuses = map[string]bool{
+	entity.PaymentStatusPaid:     true,
+	entity.PaymentStatusRejected: true,
+	entity.PaymentStatusShipped:  true,
+}
+
+// restockPaymentStatuses are the statuses that give the purchased quantity back to the product stock
+var restockPaymentStatuses = map[string]bool{
+	entity.PaymentStatusRejected:  true,
+	entity.PaymentStatusCancelled: true,
+}
+
+func canTransitPayment(from string, to string) bool {
+	for _, v := range paymentTransitions[from] {
+		if v == to {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *service) GetPayments(ctx context.Context, req request.GetPayments) ([]response.Payment, *common.Meta, int, error) {
+	if err := validator.ValidateStruct(&req); err != nil {
+		return nil, nil, http.StatusBadRequest, errors.Wrap(errorer.ErrInputRequest(err), errorer.ErrInputRequest(err).Error())
+	}
+
+	ent, meta, code, err := s.paymentRepo.FindAll(ctx, entity.GetAllPaymentFilter{
+		UserID:    req.UserID,
+		ProductID: req.ProductID,
+		Status:    req.Status,
+		StartDate: req.StartDate,
+		EndDate:   req.EndDate,
+		Limit:     req.Limit,
+		Offset:    req.Offset,
+	})
+	if err != nil {
+		return nil, nil, code, err
+	}
+
+	list := make([]response.Payment, len(ent))
+	for i, v := range ent {
+		list[i] = paymentToResponse(v)
+	}
+
+	return list, meta, http.StatusOK, nil
+}
+
+func (s *service) GetPaymentByID(ctx context.Context, id int64) (*response.Payment, int, error) {
+	ent, code, err := s.paymentRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, code, err
+	}
+
+	res := paymentToResponse(*ent)
+	return &res, code, nil
+}
+
+func (s *service) UpdatePaymentStatus(ctx context.Context, req request.UpdatePaymentStatus) (*response.Payment, int, error) {
+	if err := validator.ValidateStruct(&req); err != nil {
+		return nil, http.StatusBadRequest, errors.Wrap(errorer.ErrInputRequest(err), errorer.ErrInputRequest(err).Error())
+	}
+
+	payment, code, err := s.paymentRepo.FindByID(ctx, req.ID)
+	if err != nil {
+		return nil, code, err
+	}
+
+	if sellerPaymentStatuses[req.Status] {
+		if payment.SellerID != req.UserID {
+			return nil, http.StatusForbidden, errors.Wrap(errorer.ErrForbidden, "only the seller can set this status")
+		}
+	} else if payment.UserID != req.UserID {
+		return nil, http.StatusForbidden, errors.Wrap(errorer.ErrForbidden, "only the buyer can set this status")
+	}
+
+	if !canTransitPayment(payment.Status, req.Status) {
+		err := errors.Errorf("cannot change payment status from %s to %s", payment.Status, req.Status)
+		return nil, http.StatusBadRequest, errors.Wrap(errorer.ErrInputRequest(err), errorer.ErrInputRequest(err).Error())
+	}
+
+	updatedAt := time.Now().UnixMilli()
+	if restockPaymentStatuses[req.Status] {
+		code, err = s.paymentRepo.UpdateStatusAndRestock(ctx, payment.ID, payment.Status, req.Status, updatedAt)
+	} else {
+		code, err = s.paymentRepo.UpdateStatus(ctx, payment.ID, payment.Status, req.Status, updatedAt)
+	}
+	if err != nil {
+		return nil, code, err
+	}
+
+	payment.Status = req.Status
+	payment.UpdatedAt = updatedAt
+	res := paymentToResponse(*payment)
+
+	return &res, code, nil
+}
+
+func paymentToResponse(ent entity.Payment) response.Payment {
+	res := response.Payment{
+		ID:        strconv.Itoa(int(ent.ID)),
+		ProductID: strconv.Itoa(int(ent.ProductID)),
+		Product: &response.PaymentProduct{
+			ID:       strconv.Itoa(int(ent.ProductID)),
+			Name:     ent.ProductName,
+			Price:    ent.ProductPrice,
+			ImageURL: ent.ProductImageURL,
+			Version:  ent.ProductVersion,
+		},
+		BankAccountID:        strconv.Itoa(int(ent.BankID)),
+		Quantity:             ent.Quantity,
+		PaymentProofImageUrl: ent.PaymentProofImageURL,
+		Status:               ent.Status,
+		UserID:               ent.UserID,
+		SellerID:             ent.SellerID,
+		CreatedAt:            ent.CreatedAt,
+		UpdatedAt:            ent.UpdatedAt,
+	}
+
+	if ent.OrderID != 0 {
+		res.OrderID = strconv.Itoa(int(ent.OrderID))
+	}
+
+	if ent.VariantID != 0 {
+		res.Product.VariantID = strconv.Itoa(int(ent.VariantID))
+	}
+	res.Product.SKU = ent.VariantSKU
+
+	if ent.Bank.ID != 0 {
+		res.BankAccount = &response.Bank{
+			ID:            strconv.Itoa(int(ent.Bank.ID)),
+			Name:          ent.Bank.Name,
+			AccountName:   ent.Bank.AccountName,
+			AccountNumber: ent.Bank.AccountNumber,
+			UserID:        ent.Bank.UserID,
+		}
+	}
+
+	return res
+}
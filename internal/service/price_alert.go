@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"ecomm/internal/helper/errorer"
+	"ecomm/internal/helper/validator"
+	"ecomm/internal/model/entity"
+	"ecomm/internal/model/request"
+	"ecomm/internal/model/response"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// priceHistoryLimit is the number of price changes shown on the product detail
+const priceHistoryLimit = 30
+
+func (s *service) SubscribePriceAlert(ctx context.Context, req request.SubscribePriceAlert) (*response.PriceAlert, int, error) {
+	if err := validator.ValidateStruct(&req); err != nil {
+		return nil, http.StatusBadRequest, errors.Wrap(errorer.ErrInputRequest(err), errorer.ErrInputRequest(err).Error())
+	}
+
+	prd, code, err := s.productRepo.FindByID(ctx, req.ProductId)
+	if err != nil {
+		return nil, code, err
+	}
+	if req.TargetPrice >= prd.Price {
+		err := errorer.ErrInputRequest(errors.New("targetPrice must be below the current price"))
+		return nil, http.StatusBadRequest, errors.Wrap(err, err.Error())
+	}
+
+	alert, code, err := s.priceAlertRepo.Upsert(ctx, entity.PriceAlert{
+		ProductID:   req.ProductId,
+		UserID:      req.UserID,
+		TargetPrice: req.TargetPrice,
+		CreatedAt:   time.Now().UnixMilli(),
+	})
+	if err != nil {
+		return nil, code, err
+	}
+
+	return &response.PriceAlert{
+		ProductID:   strconv.Itoa(int(alert.ProductID)),
+		TargetPrice: alert.TargetPrice,
+		CreatedAt:   alert.CreatedAt,
+	}, http.StatusOK, nil
+}
+
+func (s *service) UnsubscribePriceAlert(ctx context.Context, productId int64, userId int64) (int, error) {
+	return s.priceAlertRepo.Delete(ctx, productId, userId)
+}
+
+func pricesToResponse(prices []entity.ProductPrice) []response.ProductPrice {
+	res := make([]response.ProductPrice, len(prices))
+	for i, p := range prices {
+		res[i] = response.ProductPrice{
+			Price:     p.Price,
+			ChangedAt: p.CreatedAt,
+		}
+	}
+	return res
+}
@@ -4,10 +4,12 @@ import (
 	"context"
 	"ecomm/internal/helper/common"
 	"ecomm/internal/helper/errorer"
+	"ecomm/internal/helper/markdown"
 	"ecomm/internal/helper/validator"
 	"ecomm/internal/model/entity"
 	"ecomm/internal/model/request"
 	"ecomm/internal/model/response"
+	"html"
 	"net/http"
 	"strconv"
 	"strings"
@@ -16,22 +18,34 @@ import (
 	"github.com/pkg/errors"
 )
 
-func (s *service) GetProducts(ctx context.Context, req request.GetProducts) ([]response.Product, *common.Meta, int, error) {
-	ent, meta, code, err := s.productRepo.FindAll(ctx, entity.GetAllProductFilter{
+func (s *service) GetProducts(ctx context.Context, req request.GetProducts) (*response.ProductList, *common.Meta, int, error) {
+	req.Facets = splitFacets(req.Facets)
+	if err := validator.ValidateStruct(&req); err != nil {
+		return nil, nil, http.StatusBadRequest, errors.Wrap(errorer.ErrInputRequest(err), errorer.ErrInputRequest(err).Error())
+	}
+
+	sorts, err := parseProductSorts(req.SortBy, req.OrderBy)
+	if err != nil {
+		return nil, nil, http.StatusBadRequest, errors.Wrap(errorer.ErrInputRequest(err), errorer.ErrInputRequest(err).Error())
+	}
+
+	filter := entity.GetAllProductFilter{
 		UserOnly:       req.UserOnly,
 		UserID:         req.UserID,
 		Limit:          req.Limit,
 		Offset:         req.Offset,
-		Tags:           req.Tags,
+		Tags:           normalizeTags(req.Tags),
+		TagMatch:       req.TagMatch,
 		Condition:      req.Condition,
+		CategoryID:     req.Category,
 		ShowEmptyStock: req.ShowEmptyStock,
 		MaxPrice:       req.MaxPrice,
 		MinPrice:       req.MinPrice,
-		SortBy:         req.SortBy,
-		OrderBy:        req.OrderBy,
+		Sorts:          sorts,
 		Search:         req.Search,
-	})
-
+		Cursor:         req.Cursor,
+	}
+	ent, meta, code, err := s.productRepo.FindAll(ctx, filter)
 	if err != nil {
 		return nil, nil, code, err
 	}
@@ -47,14 +61,65 @@ func (s *service) GetProducts(ctx context.Context, req request.GetProducts) ([]r
 			UserID:        v.UserID,
 			IsPurchasable: v.IsPurchasable,
 			Condition:     v.Condition,
-			Tags:          strings.Split(v.Tags, ","),
+			Tags:          v.Tags,
+			CategoryID:    categoryIDToResponse(v.CategoryID),
 			PurchaseCount: v.PurchaseCount,
+			PurchaseLimit: v.PurchaseLimit,
 			CreatedAt:     v.CreatedAt,
 			UpdatedAt:     v.UpdatedAt,
+			Highlight:     sanitizeHighlight(v.Highlight),
+			Images:        imagesToResponse(v.Images),
+		}
+	}
+
+	res := response.ProductList{Products: list}
+	// offer the closest known term when a search found nothing, usually a misspelling
+	if req.Search != "" && req.Cursor == "" && len(list) == 0 {
+		suggestions, code, err := s.productRepo.Suggest(ctx, entity.GetSuggestionFilter{
+			Query: strings.TrimSpace(req.Search),
+			Limit: 1,
+		})
+		if err != nil {
+			return nil, nil, code, err
+		}
+		if len(suggestions) > 0 && !strings.EqualFold(suggestions[0].Term, strings.TrimSpace(req.Search)) {
+			res.DidYouMean = suggestions[0].Term
+		}
+	}
+
+	if len(req.Facets) > 0 {
+		facets, code, err := s.productRepo.FindFacets(ctx, filter, req.Facets)
+		if err != nil {
+			return nil, nil, code, err
+		}
+		res.Facets = facetsToResponse(*facets)
+	}
+
+	return &res, meta, http.StatusOK, nil
+}
+
+func (s *service) GetSuggestions(ctx context.Context, req request.GetSuggestions) ([]response.Suggestion, int, error) {
+	if err := validator.ValidateStruct(&req); err != nil {
+		return nil, http.StatusBadRequest, errors.Wrap(errorer.ErrInputRequest(err), errorer.ErrInputRequest(err).Error())
+	}
+
+	ent, code, err := s.productRepo.Suggest(ctx, entity.GetSuggestionFilter{
+		Query: strings.TrimSpace(req.Query),
+		Limit: req.Limit,
+	})
+	if err != nil {
+		return nil, code, err
+	}
+
+	list := make([]response.Suggestion, len(ent))
+	for i, v := range ent {
+		list[i] = response.Suggestion{
+			Term: v.Term,
+			Kind: v.Kind,
 		}
 	}
 
-	return list, meta, http.StatusOK, nil
+	return list, http.StatusOK, nil
 }
 
 func (s *service) GetProductByID(ctx context.Context, id int64) (*response.Product, int, error) {
@@ -63,23 +128,53 @@ func (s *service) GetProductByID(ctx context.Context, id int64) (*response.Produ
 		return nil, code, err
 	}
 
+	prices, code, err := s.productRepo.FindPriceHistory(ctx, id, priceHistoryLimit)
+	if err != nil {
+		return nil, code, err
+	}
+
 	return &response.Product{
-		ID:            strconv.Itoa(int(ent.ID)),
-		Name:          ent.Name,
-		Price:         ent.Price,
-		ImageURL:      ent.ImageURL,
-		Stock:         ent.Stock,
-		UserID:        ent.UserID,
-		IsPurchasable: ent.IsPurchasable,
-		Condition:     ent.Condition,
-		Tags:          strings.Split(ent.Tags, ","),
-		PurchaseCount: 0,
-		CreatedAt:     ent.CreatedAt,
-		UpdatedAt:     ent.UpdatedAt,
+		ID:              strconv.Itoa(int(ent.ID)),
+		Name:            ent.Name,
+		Description:     ent.Description,
+		DescriptionHTML: markdown.ToHTML(ent.Description),
+		Price:           ent.Price,
+		ImageURL:        ent.ImageURL,
+		Stock:           ent.Stock,
+		UserID:          ent.UserID,
+		IsPurchasable:   ent.IsPurchasable,
+		Condition:       ent.Condition,
+		Tags:            ent.Tags,
+		CategoryID:      categoryIDToResponse(ent.CategoryID),
+		PurchaseCount:   0,
+		PurchaseLimit:   ent.PurchaseLimit,
+		CreatedAt:       ent.CreatedAt,
+		UpdatedAt:       ent.UpdatedAt,
+		Images:          imagesToResponse(ent.Images),
+		Options:         optionsToResponse(ent.Options),
+		Variants:        variantsToResponse(*ent),
+		PriceHistory:    pricesToResponse(prices),
+		Version:         ent.Version,
 	}, code, nil
 }
 
 func (s *service) CreateProduct(ctx context.Context, req request.Product) (*response.Product, int, error) {
+	prd, code, err := s.productFromRequest(ctx, req)
+	if err != nil {
+		return nil, code, err
+	}
+
+	_, code, err = s.productRepo.Create(ctx, *prd)
+
+	if err != nil {
+		return nil, code, err
+	}
+
+	return nil, code, nil
+}
+
+// productFromRequest validates a new product the same way for every way of creating one
+func (s *service) productFromRequest(ctx context.Context, req request.Product) (*entity.Product, int, error) {
 	if err := validator.ValidateStruct(&req); err != nil {
 		return nil, http.StatusBadRequest, errors.Wrap(errorer.ErrInputRequest(err), errorer.ErrInputRequest(err).Error())
 	}
@@ -88,25 +183,34 @@ func (s *service) CreateProduct(ctx context.Context, req request.Product) (*resp
 		return nil, http.StatusBadRequest, errors.Wrap(errorer.ErrInputRequest(errors.New("invalid condition")), "invalid request condition")
 	}
 
-	_, code, err := s.productRepo.Create(ctx, entity.Product{
+	categoryId, _ := strconv.Atoi(req.CategoryId)
+	if code, err := s.validateCategory(ctx, int64(categoryId)); err != nil {
+		return nil, code, err
+	}
+
+	options, variants, err := variantsFromRequest(req.Options, req.Variants)
+	if err != nil {
+		return nil, http.StatusBadRequest, errors.Wrap(errorer.ErrInputRequest(err), errorer.ErrInputRequest(err).Error())
+	}
+
+	return &entity.Product{
 		Name:          req.Name,
+		Description:   strings.TrimSpace(req.Description),
 		Price:         req.Price,
 		ImageURL:      req.ImageURL,
 		Stock:         req.Stock,
 		UserID:        req.UserID,
 		IsPurchasable: req.IsPurchasable,
 		Condition:     req.Condition,
-		Tags:          strings.Join(req.Tags, ","),
+		Tags:          normalizeTags(req.Tags),
+		CategoryID:    int64(categoryId),
 		PurchaseCount: 0,
+		PurchaseLimit: req.PurchaseLimit,
+		Options:       options,
+		Variants:      variants,
 		CreatedAt:     time.Now().UnixMilli(),
 		UpdatedAt:     time.Now().UnixMilli(),
-	})
-
-	if err != nil {
-		return nil, code, err
-	}
-
-	return nil, code, nil
+	}, http.StatusOK, nil
 }
 
 func (s *service) UpdateProductByID(ctx context.Context, req request.UpdateProduct) (*response.Product, int, error) {
@@ -118,14 +222,37 @@ func (s *service) UpdateProductByID(ctx context.Context, req request.UpdateProdu
 		return nil, http.StatusBadRequest, errors.Wrap(errorer.ErrInputRequest(errors.New("invalid condition")), "invalid request condition")
 	}
 
+	categoryId, _ := strconv.Atoi(req.CategoryId)
+	if code, err := s.validateCategory(ctx, int64(categoryId)); err != nil {
+		return nil, code, err
+	}
+
+	// omitted options and variants are left untouched, empty lists remove them
+	var options []entity.ProductOption
+	var variants []entity.ProductVariant
+	if req.Options != nil || req.Variants != nil {
+		var err error
+		options, variants, err = variantsFromRequest(req.Options, req.Variants)
+		if err != nil {
+			return nil, http.StatusBadRequest, errors.Wrap(errorer.ErrInputRequest(err), errorer.ErrInputRequest(err).Error())
+		}
+	}
+
 	_, code, err := s.productRepo.UpdateByID(ctx, entity.Product{
 		ID:            req.ID,
 		Name:          req.Name,
+		Description:   strings.TrimSpace(req.Description),
 		Price:         req.Price,
 		ImageURL:      req.ImageURL,
 		IsPurchasable: req.IsPurchasable,
 		Condition:     req.Condition,
-		Tags:          strings.Join(req.Tags, ","),
+		Tags:          normalizeTags(req.Tags),
+		CategoryID:    int64(categoryId),
+		PurchaseLimit: req.PurchaseLimit,
+		Options:       options,
+		Variants:      variants,
+		UserID:        req.UserID,
+		Version:       req.Version,
 		UpdatedAt:     time.Now().UnixMilli(),
 	})
 
@@ -141,7 +268,21 @@ func (s *service) UpdateProductStockByID(ctx context.Context, req request.Update
 		return http.StatusBadRequest, errors.Wrap(errorer.ErrInputRequest(err), errorer.ErrInputRequest(err).Error())
 	}
 
-	code, err := s.productRepo.UpdateStockByID(ctx, req.ID, req.Stock)
+	prd, code, err := s.productRepo.FindByID(ctx, req.ID)
+	if err != nil {
+		return code, err
+	}
+
+	// the stock of a product with variants is the sum of its variants stock
+	if req.VariantId != "" {
+		variantId, _ := strconv.Atoi(req.VariantId)
+		return s.productRepo.UpdateVariantStockByID(ctx, req.ID, int64(variantId), req.Stock, req.Version, req.UserID)
+	}
+	if prd.HasVariants {
+		return http.StatusBadRequest, errors.Wrap(errorer.ErrInputRequest(errors.New("variantId is required for products with variants")), "variantId is required for products with variants")
+	}
+
+	code, err = s.productRepo.UpdateStockByID(ctx, req.ID, req.Stock, req.Version, req.UserID)
 
 	if err != nil {
 		return code, err
@@ -150,18 +291,31 @@ func (s *service) UpdateProductStockByID(ctx context.Context, req request.Update
 	return code, nil
 }
 
-func (s *service) DeleteProductByID(ctx context.Context, id int64) (int, error) {
+func (s *service) DeleteProductByID(ctx context.Context, id int64, version int, userId int64) (int, error) {
 	if id == 0 {
 		return http.StatusBadRequest, errors.Wrap(errors.New("invalid product id"), "invalid product id")
 	}
 
-	code, err := s.productRepo.DeleteByID(ctx, id)
+	code, err := s.productRepo.DeleteByID(ctx, id, version, userId)
 	if err != nil {
 		return code, err
 	}
 	return http.StatusOK, nil
 }
 
+func (s *service) RestoreProductByID(ctx context.Context, id int64, userId int64) (int, error) {
+	if id == 0 {
+		return http.StatusBadRequest, errors.Wrap(errors.New("invalid product id"), "invalid product id")
+	}
+
+	return s.productRepo.RestoreByID(ctx, id, userId)
+}
+
+// PurgeDeletedProducts removes the products deleted longer than the retention period ago
+func (s *service) PurgeDeletedProducts(ctx context.Context) (int64, int, error) {
+	return s.productRepo.PurgeDeleted(ctx, time.Now().Add(-s.cfg.ProductRetention).UnixMilli())
+}
+
 func (s *service) GetProductWithSellerByID(ctx context.Context, id int64) (*response.Product, *response.SellerDetail, int, error) {
 	prd, code, err := s.GetProductByID(ctx, id)
 	if err != nil {
@@ -208,26 +362,269 @@ func (s *service) GetProductWithSellerByID(ctx context.Context, id int64) (*resp
 	return prd, &seller, code, nil
 }
 
-func (s *service) PurchaseProduct(ctx context.Context, req request.PurchaseProduct) (int, error) {
+func (s *service) PurchaseProduct(ctx context.Context, req request.PurchaseProduct) (*response.Payment, int, error) {
 	if err := validator.ValidateStruct(&req); err != nil {
-		return http.StatusBadRequest, errors.Wrap(errorer.ErrInputRequest(err), errorer.ErrInputRequest(err).Error())
+		return nil, http.StatusBadRequest, errors.Wrap(errorer.ErrInputRequest(err), errorer.ErrInputRequest(err).Error())
+	}
+	bankId, err := strconv.Atoi(req.BankAccountId)
+	if err != nil {
+		return nil, http.StatusBadRequest, errors.Wrap(errorer.ErrInputRequest(errors.New("invalid bank account id")), "invalid bank account id")
 	}
-	bankId, _ := strconv.Atoi(req.BankAccountId)
 	bank, _, err := s.bankRepo.FindByID(ctx, int64(bankId))
 	if err != nil {
-		return http.StatusBadRequest, err
+		return nil, http.StatusBadRequest, err
 	}
 	prd, code, err := s.productRepo.FindByID(ctx, req.ProductId)
 
 	if err != nil {
-		return code, err
+		return nil, code, err
 	}
 
 	if prd.UserID != bank.UserID {
-		return http.StatusBadRequest, errors.Wrap(errorer.ErrInputRequest(errors.New("bank account not owned by seller")), "bank account not owned by seller")
+		return nil, http.StatusBadRequest, errors.Wrap(errorer.ErrInputRequest(errors.New("bank account not owned by seller")), "bank account not owned by seller")
+	}
+
+	variantId, _ := strconv.Atoi(req.VariantId)
+	if code, err := s.checkPurchase(ctx, Purchase{BuyerID: req.UserID, Product: prd, Quantity: req.Quantity, VariantID: int64(variantId)}); err != nil {
+		return nil, code, err
+	}
+
+	reservationId, _ := strconv.Atoi(req.ReservationId)
+	payment, code, err := s.paymentRepo.Create(ctx, entity.Payment{
+		ReservationID:        int64(reservationId),
+		VariantID:            int64(variantId),
+		UserID:               req.UserID,
+		ProductID:            req.ProductId,
+		BankID:               bank.ID,
+		Quantity:             req.Quantity,
+		PaymentProofImageURL: req.PaymentProofImageUrl,
+		CreatedAt:            time.Now().UnixMilli(),
+		UpdatedAt:            time.Now().UnixMilli(),
+	})
+	if err != nil {
+		return nil, code, err
+	}
+
+	payment.Bank = *bank
+	res := paymentToResponse(*payment)
+
+	return &res, code, nil
+}
+
+func categoryIDToResponse(id int64) string {
+	if id == 0 {
+		return ""
+	}
+	return strconv.Itoa(int(id))
+}
+
+// sanitizeHighlight escapes the highlighted snippet while keeping the <mark> tags added by the search
+func sanitizeHighlight(highlight string) string {
+	escaped := html.EscapeString(highlight)
+	escaped = strings.ReplaceAll(escaped, "&lt;mark&gt;", "<mark>")
+	return strings.ReplaceAll(escaped, "&lt;/mark&gt;", "</mark>")
+}
+
+// productSortDescByDefault lists the sort fields where the best products come first unless ordered otherwise
+var productSortDescByDefault = map[string]bool{
+	entity.ProductSortDate:          false,
+	entity.ProductSortPrice:         false,
+	entity.ProductSortPurchaseCount: false,
+	entity.ProductSortStock:         false,
+	entity.ProductSortName:          false,
+	entity.ProductSortRelevance:     true,
+	entity.ProductSortTrending:      true,
+}
+
+// parseProductSorts parses a sort list like "price,-date". Fields without a sign follow orderBy,
+// or their own default direction when orderBy is empty
+func parseProductSorts(sortBy string, orderBy string) ([]entity.ProductSort, error) {
+	sorts := []entity.ProductSort{}
+	seen := map[string]bool{}
+	for _, field := range strings.Split(sortBy, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		sort := entity.ProductSort{}
+		switch {
+		case strings.HasPrefix(field, "-"):
+			sort.Desc = true
+			field = field[1:]
+		case strings.HasPrefix(field, "+"):
+			field = field[1:]
+		case orderBy != "":
+			sort.Desc = strings.EqualFold(orderBy, "DESC")
+		default:
+			sort.Desc = productSortDescByDefault[field]
+		}
+
+		if _, ok := productSortDescByDefault[field]; !ok {
+			return nil, errors.Errorf("unknown sort field %q", field)
+		}
+		if seen[field] {
+			return nil, errors.Errorf("duplicate sort field %q", field)
+		}
+		seen[field] = true
+		sort.Field = field
+		sorts = append(sorts, sort)
+	}
+	return sorts, nil
+}
+
+// variantsFromRequest checks that every variant picks exactly one known value of each option,
+// with no two variants sharing a SKU or a combination of values
+func variantsFromRequest(reqOptions []request.ProductOption, reqVariants []request.ProductVariant) ([]entity.ProductOption, []entity.ProductVariant, error) {
+	if (len(reqOptions) == 0) != (len(reqVariants) == 0) {
+		return nil, nil, errors.New("options and variants must be given together")
 	}
 
-	code, err = s.productRepo.Purchase(ctx, req.ProductId, req.Quantity)
+	options := make([]entity.ProductOption, len(reqOptions))
+	optionNames := map[string]bool{}
+	for i, v := range reqOptions {
+		name := strings.TrimSpace(v.Name)
+		if optionNames[strings.ToLower(name)] {
+			return nil, nil, errors.Errorf("duplicate option %q", name)
+		}
+		optionNames[strings.ToLower(name)] = true
+
+		values := []string{}
+		seen := map[string]bool{}
+		for _, value := range v.Values {
+			value = strings.TrimSpace(value)
+			if seen[value] {
+				return nil, nil, errors.Errorf("duplicate value %q of option %q", value, name)
+			}
+			seen[value] = true
+			values = append(values, value)
+		}
+		options[i] = entity.ProductOption{Name: name, Values: values}
+	}
+
+	variants := make([]entity.ProductVariant, len(reqVariants))
+	skus := map[string]bool{}
+	combinations := map[string]bool{}
+	for i, v := range reqVariants {
+		sku := strings.TrimSpace(v.SKU)
+		if skus[sku] {
+			return nil, nil, errors.Errorf("duplicate sku %q", sku)
+		}
+		skus[sku] = true
+
+		if len(v.Options) != len(options) {
+			return nil, nil, errors.Errorf("variant %q must have one value for each option", sku)
+		}
+		values := make([]string, len(v.Options))
+		for j, value := range v.Options {
+			values[j] = strings.TrimSpace(value)
+			known := false
+			for _, optionValue := range options[j].Values {
+				known = known || optionValue == values[j]
+			}
+			if !known {
+				return nil, nil, errors.Errorf("variant %q has unknown value %q for option %q", sku, values[j], options[j].Name)
+			}
+		}
+		combination := strings.Join(values, "\x00")
+		if combinations[combination] {
+			return nil, nil, errors.Errorf("variant %q duplicates the options of another variant", sku)
+		}
+		combinations[combination] = true
+
+		variants[i] = entity.ProductVariant{
+			SKU:          sku,
+			OptionValues: values,
+			Price:        v.Price,
+			Stock:        v.Stock,
+			ImageURL:     v.ImageURL,
+		}
+	}
+
+	return options, variants, nil
+}
+
+func optionsToResponse(options []entity.ProductOption) []response.ProductOption {
+	list := make([]response.ProductOption, len(options))
+	for i, v := range options {
+		list[i] = response.ProductOption{Name: v.Name, Values: v.Values}
+	}
+	return list
+}
+
+// variantsToResponse returns the variant matrix of the product with the product price and image as fallback
+func variantsToResponse(prd entity.Product) []response.ProductVariant {
+	list := make([]response.ProductVariant, len(prd.Variants))
+	for i, v := range prd.Variants {
+		variant := response.ProductVariant{
+			ID:       strconv.Itoa(int(v.ID)),
+			SKU:      v.SKU,
+			Options:  map[string]string{},
+			Price:    prd.Price,
+			Stock:    v.Stock,
+			ImageURL: prd.ImageURL,
+		}
+		for j, value := range v.OptionValues {
+			if j < len(prd.Options) {
+				variant.Options[prd.Options[j].Name] = value
+			}
+		}
+		if v.Price != nil {
+			variant.Price = *v.Price
+		}
+		if v.ImageURL != "" {
+			variant.ImageURL = v.ImageURL
+		}
+		list[i] = variant
+	}
+	return list
+}
+
+// splitFacets accepts both repeated and comma separated facet names and drops duplicates
+func splitFacets(facets []string) []string {
+	seen := map[string]bool{}
+	list := []string{}
+	for _, v := range facets {
+		for _, facet := range strings.Split(v, ",") {
+			facet = strings.ToLower(strings.TrimSpace(facet))
+			if facet == "" || seen[facet] {
+				continue
+			}
+			seen[facet] = true
+			list = append(list, facet)
+		}
+	}
+	return list
+}
+
+func facetsToResponse(ent entity.ProductFacets) *response.ProductFacets {
+	res := response.ProductFacets{}
+	for _, v := range ent.Conditions {
+		res.Conditions = append(res.Conditions, response.FacetCount{Value: v.Value, Count: v.Count})
+	}
+	for _, v := range ent.Tags {
+		res.Tags = append(res.Tags, response.FacetCount{Value: v.Value, Count: v.Count})
+	}
+	for _, v := range ent.PriceBuckets {
+		res.PriceBuckets = append(res.PriceBuckets, response.PriceBucket{Min: v.Min, Max: v.Max, Count: v.Count})
+	}
+	if ent.Stock != nil {
+		res.Stock = &response.StockFacet{InStock: ent.Stock.InStock, OutOfStock: ent.Stock.OutOfStock}
+	}
+	return &res
+}
 
-	return code, err
+// normalizeTags lower-cases and trims the tags and drops empty and duplicate ones
+func normalizeTags(tags []string) []string {
+	seen := map[string]bool{}
+	list := []string{}
+	for _, v := range tags {
+		tag := strings.ToLower(strings.TrimSpace(v))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		list = append(list, tag)
+	}
+	return list
 }
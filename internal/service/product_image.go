@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"ecomm/internal/helper/errorer"
+	"ecomm/internal/helper/validator"
+	"ecomm/internal/model/entity"
+	"ecomm/internal/model/request"
+	"ecomm/internal/model/response"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// maxProductImages is the most images a product gallery can hold
+const maxProductImages = 10
+
+func (s *service) AddProductImage(ctx context.Context, req request.AddProductImage) ([]response.ProductImage, int, error) {
+	if err := validator.ValidateStruct(&req); err != nil {
+		return nil, http.StatusBadRequest, errors.Wrap(errorer.ErrInputRequest(err), errorer.ErrInputRequest(err).Error())
+	}
+
+	_, code, err := s.productImageRepo.Create(ctx, entity.ProductImage{
+		ProductID: req.ProductId,
+		URL:       req.URL,
+		AltText:   req.AltText,
+		CreatedAt: time.Now().UnixMilli(),
+	}, maxProductImages, req.UserID)
+	if err != nil {
+		return nil, code, err
+	}
+
+	return s.getProductImages(ctx, req.ProductId)
+}
+
+func (s *service) ReorderProductImages(ctx context.Context, req request.ReorderProductImages) ([]response.ProductImage, int, error) {
+	if err := validator.ValidateStruct(&req); err != nil {
+		return nil, http.StatusBadRequest, errors.Wrap(errorer.ErrInputRequest(err), errorer.ErrInputRequest(err).Error())
+	}
+
+	ids := make([]int64, len(req.ImageIds))
+	for i, v := range req.ImageIds {
+		id, _ := strconv.Atoi(v)
+		ids[i] = int64(id)
+	}
+
+	code, err := s.productImageRepo.Reorder(ctx, req.ProductId, ids, req.UserID)
+	if err != nil {
+		return nil, code, err
+	}
+
+	return s.getProductImages(ctx, req.ProductId)
+}
+
+func (s *service) DeleteProductImage(ctx context.Context, productId int64, imageId int64, userId int64) ([]response.ProductImage, int, error) {
+	code, err := s.productImageRepo.DeleteByID(ctx, productId, imageId, userId)
+	if err != nil {
+		return nil, code, err
+	}
+
+	return s.getProductImages(ctx, productId)
+}
+
+func (s *service) getProductImages(ctx context.Context, productId int64) ([]response.ProductImage, int, error) {
+	images, code, err := s.productImageRepo.FindByProductID(ctx, productId)
+	if err != nil {
+		return nil, code, err
+	}
+	return imagesToResponse(images), http.StatusOK, nil
+}
+
+func imagesToResponse(images []entity.ProductImage) []response.ProductImage {
+	list := make([]response.ProductImage, len(images))
+	for i, v := range images {
+		list[i] = response.ProductImage{
+			ID:       strconv.Itoa(int(v.ID)),
+			URL:      v.URL,
+			AltText:  v.AltText,
+			Position: v.Position,
+		}
+	}
+	return list
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"ecomm/internal/helper/errorer"
+	"ecomm/internal/helper/validator"
+	"ecomm/internal/model/entity"
+	"ecomm/internal/model/request"
+	"ecomm/internal/model/response"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// maxImportFileSize is the largest CSV file accepted by the product import
+	maxImportFileSize = 5_000_000
+	// maxImportRows is the most products a single import can create
+	maxImportRows = 1000
+	// importTagSeparator separates the tags of a product inside the tags column
+	importTagSeparator = "|"
+)
+
+const (
+	productImportStatusValid   = "valid"
+	productImportStatusCreated = "created"
+	productImportStatusFailed  = "failed"
+)
+
+// importColumns are the columns understood by the product import, the required ones must be in the header
+var importColumns = map[string]bool{
+	"name":                  true,
+	"description":           false,
+	"price":                 true,
+	"imageurl":              true,
+	"stock":                 true,
+	"condition":             true,
+	"tags":                  true,
+	"categoryid":            true,
+	"ispurchasable":         false,
+	"purchaselimitperbuyer": false,
+}
+
+// ImportProducts validates every row of the CSV file with the rules of CreateProduct and creates the valid
+// ones in a single transaction, unless it is a dry run. Invalid rows are reported and skipped
+func (s *service) ImportProducts(ctx context.Context, req request.ImportProducts, file *multipart.FileHeader) (*response.ProductImport, int, error) {
+	if err := validator.ValidateStruct(&req); err != nil {
+		return nil, http.StatusBadRequest, errors.Wrap(errorer.ErrInputRequest(err), errorer.ErrInputRequest(err).Error())
+	}
+	if file.Size == 0 || file.Size > maxImportFileSize {
+		err := errorer.ErrInputRequest(fmt.Errorf("file size must be between 1B and %dMB", maxImportFileSize/1_000_000))
+		return nil, http.StatusBadRequest, errors.Wrap(err, err.Error())
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	defer src.Close()
+
+	reader := csv.NewReader(src)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		err := errorer.ErrInputRequest(fmt.Errorf("invalid csv header: %s", err.Error()))
+		return nil, http.StatusBadRequest, errors.Wrap(err, err.Error())
+	}
+	columns, err := importColumnIndexes(header)
+	if err != nil {
+		return nil, http.StatusBadRequest, errors.Wrap(errorer.ErrInputRequest(err), errorer.ErrInputRequest(err).Error())
+	}
+
+	res := response.ProductImport{DryRun: req.DryRun, Rows: []response.ProductImportRow{}}
+	products := []entity.Product{}
+	// productRows maps each product to its entry of res.Rows
+	productRows := []int{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			err := errorer.ErrInputRequest(fmt.Errorf("invalid csv: %s", err.Error()))
+			return nil, http.StatusBadRequest, errors.Wrap(err, err.Error())
+		}
+		if len(res.Rows) == maxImportRows {
+			err := errorer.ErrInputRequest(fmt.Errorf("a file can hold at most %d products", maxImportRows))
+			return nil, http.StatusBadRequest, errors.Wrap(err, err.Error())
+		}
+
+		line, _ := reader.FieldPos(0)
+		row := response.ProductImportRow{Line: line, Status: productImportStatusValid}
+		prd, code, err := s.importProductRow(ctx, columns, record, req.UserID)
+		if prd != nil {
+			row.Name = prd.Name
+		} else if i, ok := columns["name"]; ok && i < len(record) {
+			row.Name = record[i]
+		}
+		if err != nil {
+			if code != http.StatusBadRequest {
+				return nil, code, err
+			}
+			row.Status = productImportStatusFailed
+			row.Error = errors.Cause(err).Error()
+			res.Failed++
+		} else {
+			products = append(products, *prd)
+			productRows = append(productRows, len(res.Rows))
+			res.Valid++
+		}
+		res.Rows = append(res.Rows, row)
+	}
+	res.Total = len(res.Rows)
+
+	if req.DryRun || len(products) == 0 {
+		return &res, http.StatusOK, nil
+	}
+
+	created, code, err := s.productRepo.CreateMany(ctx, products)
+	if err != nil {
+		return nil, code, err
+	}
+	for i, prd := range created {
+		row := &res.Rows[productRows[i]]
+		row.Status = productImportStatusCreated
+		row.ProductID = strconv.Itoa(int(prd.ID))
+	}
+	res.Created = len(created)
+
+	return &res, http.StatusOK, nil
+}
+
+// importColumnIndexes maps the lower cased column names of the header to their index
+func importColumnIndexes(header []string) (map[string]int, error) {
+	columns := map[string]int{}
+	for i, name := range header {
+		// spreadsheet exports often start with a byte order mark
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if _, ok := importColumns[name]; !ok {
+			return nil, fmt.Errorf("unknown column %q", header[i])
+		}
+		if _, ok := columns[name]; ok {
+			return nil, fmt.Errorf("duplicate column %q", header[i])
+		}
+		columns[name] = i
+	}
+
+	missing := []string{}
+	for name, required := range importColumns {
+		if _, ok := columns[name]; required && !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("missing columns: %s", strings.Join(missing, ", "))
+	}
+
+	return columns, nil
+}
+
+// importProductRow reads one CSV record into a product request and validates it like CreateProduct does
+func (s *service) importProductRow(ctx context.Context, columns map[string]int, record []string, userId int64) (*entity.Product, int, error) {
+	value := func(column string) string {
+		i, ok := columns[column]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+	number := func(column string) (int, error) {
+		v := value(column)
+		if v == "" {
+			return 0, nil
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			err := errorer.ErrInputRequest(fmt.Errorf("%s must be a whole number", column))
+			return 0, errors.Wrap(err, err.Error())
+		}
+		return n, nil
+	}
+
+	if len(record) != len(columns) {
+		err := errorer.ErrInputRequest(fmt.Errorf("expected %d fields, got %d", len(columns), len(record)))
+		return nil, http.StatusBadRequest, errors.Wrap(err, err.Error())
+	}
+
+	req := request.Product{
+		Name:        value("name"),
+		Description: value("description"),
+		ImageURL:    value("imageurl"),
+		Condition:   value("condition"),
+		CategoryId:  value("categoryid"),
+		UserID:      userId,
+	}
+	if tags := value("tags"); tags != "" {
+		req.Tags = strings.Split(tags, importTagSeparator)
+	}
+
+	var err error
+	if req.Price, err = number("price"); err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	if req.Stock, err = number("stock"); err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	if req.PurchaseLimit, err = number("purchaselimitperbuyer"); err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	if v := value("ispurchasable"); v != "" {
+		if req.IsPurchasable, err = strconv.ParseBool(v); err != nil {
+			err := errorer.ErrInputRequest(errors.New("ispurchasable must be true or false"))
+			return nil, http.StatusBadRequest, errors.Wrap(err, err.Error())
+		}
+	}
+
+	return s.productFromRequest(ctx, req)
+}
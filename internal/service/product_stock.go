@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"ecomm/internal/helper/errorer"
+	"ecomm/internal/helper/validator"
+	"ecomm/internal/model/entity"
+	"ecomm/internal/model/request"
+	"ecomm/internal/model/response"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	productStockStatusUpdated = "updated"
+	productStockStatusFailed  = "failed"
+	productStockStatusSkipped = "skipped"
+)
+
+// UpdateProductStocks applies a stock-take to several products of the seller at once. The batch is applied
+// entirely or not at all, the result of every item tells which ones were refused
+func (s *service) UpdateProductStocks(ctx context.Context, req request.UpdateProductStocks) ([]response.ProductStockUpdate, int, error) {
+	if err := validator.ValidateStruct(&req); err != nil {
+		return nil, http.StatusBadRequest, errors.Wrap(errorer.ErrInputRequest(err), errorer.ErrInputRequest(err).Error())
+	}
+
+	updates := make([]entity.ProductStockUpdate, len(req.Items))
+	seen := make(map[int64]bool, len(req.Items))
+	for i, item := range req.Items {
+		productId, _ := strconv.Atoi(item.ProductId)
+		if seen[int64(productId)] {
+			err := errorer.ErrInputRequest(fmt.Errorf("product %s is listed more than once", item.ProductId))
+			return nil, http.StatusBadRequest, errors.Wrap(err, err.Error())
+		}
+		seen[int64(productId)] = true
+
+		updates[i] = entity.ProductStockUpdate{
+			ProductID: int64(productId),
+			Stock:     *item.Stock,
+			Price:     item.Price,
+			Version:   item.Version,
+		}
+	}
+
+	results, code, err := s.productRepo.UpdateStocks(ctx, req.UserID, updates)
+	if results == nil {
+		return nil, code, err
+	}
+
+	res := make([]response.ProductStockUpdate, len(results))
+	for i, v := range results {
+		res[i] = response.ProductStockUpdate{
+			ProductID: strconv.Itoa(int(v.ProductID)),
+			Status:    productStockStatusUpdated,
+			Stock:     v.Stock,
+			Price:     v.Price,
+			Version:   v.Version,
+		}
+		if v.Err != nil {
+			res[i].Status = productStockStatusFailed
+			res[i].Error = errors.Cause(v.Err).Error()
+		} else if err != nil {
+			res[i].Status = productStockStatusSkipped
+		}
+	}
+
+	return res, code, err
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"ecomm/internal/helper/common"
+	"ecomm/internal/helper/errorer"
+	"ecomm/internal/helper/validator"
+	"ecomm/internal/model/entity"
+	"ecomm/internal/model/request"
+	"ecomm/internal/model/response"
+	"net/http"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+func (s *service) GetProductHistory(ctx context.Context, req request.GetProductHistory) ([]response.ProductVersion, *common.Meta, int, error) {
+	if err := validator.ValidateStruct(&req); err != nil {
+		return nil, nil, http.StatusBadRequest, errors.Wrap(errorer.ErrInputRequest(err), errorer.ErrInputRequest(err).Error())
+	}
+
+	versions, meta, code, err := s.productRepo.FindVersions(ctx, entity.GetProductVersionFilter{
+		ProductID: req.ProductId,
+		UserID:    req.UserID,
+		Limit:     req.Limit,
+		Offset:    req.Offset,
+	})
+	if err != nil {
+		return nil, nil, code, err
+	}
+
+	res := make([]response.ProductVersion, len(versions))
+	for i, v := range versions {
+		res[i] = response.ProductVersion{
+			Version:   v.Version,
+			Action:    v.Action,
+			UserID:    strconv.Itoa(int(v.UserID)),
+			CreatedAt: v.CreatedAt,
+			Product:   v.Snapshot,
+		}
+	}
+
+	return res, meta, http.StatusOK, nil
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"ecomm/internal/helper/errorer"
+	"ecomm/internal/model/entity"
+	"ecomm/internal/repository"
+	"fmt"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// Reasons returned to clients when a purchase rule rejects a purchase
+const (
+	PurchaseRejectedNotPurchasable   = "product_not_purchasable"
+	PurchaseRejectedSelfPurchase     = "self_purchase"
+	PurchaseRejectedQuantityExceeded = entity.PurchaseRejectedQuantityExceeded
+	PurchaseRejectedBuyerLimit       = entity.PurchaseRejectedBuyerLimit
+	PurchaseRejectedSellerSuspended  = "seller_suspended"
+	PurchaseRejectedVariantRequired  = "variant_required"
+	PurchaseRejectedUnknownVariant   = "unknown_variant"
+)
+
+// Purchase is what a purchase rule decides on
+type Purchase struct {
+	BuyerID  int64
+	Product  *entity.Product
+	Quantity int
+	// VariantID is the variant bought, 0 when buying a product without variants
+	VariantID int64
+}
+
+// PurchaseRule allows or rejects a purchase. A rejection is returned as an errorer.RejectionError
+type PurchaseRule interface {
+	Check(ctx context.Context, purchase Purchase) (int, error)
+}
+
+// defaultPurchaseRules is the pipeline every purchase, reservation and checkout goes through
+func defaultPurchaseRules(cfg Config, userRepo repository.UserRepository, paymentRepo repository.PaymentRepository) []PurchaseRule {
+	return []PurchaseRule{
+		purchasableRule{},
+		variantRule{},
+		selfPurchaseRule{},
+		maxQuantityRule{max: cfg.MaxPurchaseQuantity},
+		buyerLimitRule{paymentRepo: paymentRepo},
+		sellerSuspendedRule{userRepo: userRepo},
+	}
+}
+
+// checkPurchase runs the purchase through every rule and stops at the first rejection
+func (s *service) checkPurchase(ctx context.Context, purchase Purchase) (int, error) {
+	for _, rule := range s.purchaseRules {
+		if code, err := rule.Check(ctx, purchase); err != nil {
+			return code, err
+		}
+	}
+	return http.StatusOK, nil
+}
+
+func rejectPurchase(reason string, message string) (int, error) {
+	return http.StatusBadRequest, errors.Wrap(errorer.ErrRejected(reason, message), message)
+}
+
+type purchasableRule struct{}
+
+func (purchasableRule) Check(ctx context.Context, purchase Purchase) (int, error) {
+	if !purchase.Product.IsPurchasable {
+		return rejectPurchase(PurchaseRejectedNotPurchasable, "product is not purchasable")
+	}
+	return http.StatusOK, nil
+}
+
+type variantRule struct{}
+
+// Check requires a variant of the product for products sold through variants, and only for those.
+// Product.Variants is only loaded when fetching a single product, otherwise the variant is checked on purchase
+func (variantRule) Check(ctx context.Context, purchase Purchase) (int, error) {
+	if !purchase.Product.HasVariants {
+		if purchase.VariantID != 0 {
+			return rejectPurchase(PurchaseRejectedUnknownVariant, "product has no variants")
+		}
+		return http.StatusOK, nil
+	}
+
+	if purchase.VariantID == 0 {
+		return rejectPurchase(PurchaseRejectedVariantRequired, "a variant of the product must be chosen")
+	}
+	if purchase.Product.Variants == nil {
+		return http.StatusOK, nil
+	}
+	for _, v := range purchase.Product.Variants {
+		if v.ID == purchase.VariantID {
+			return http.StatusOK, nil
+		}
+	}
+	return rejectPurchase(PurchaseRejectedUnknownVariant, "variant does not belong to the product")
+}
+
+type selfPurchaseRule struct{}
+
+func (selfPurchaseRule) Check(ctx context.Context, purchase Purchase) (int, error) {
+	if purchase.Product.UserID == purchase.BuyerID {
+		return rejectPurchase(PurchaseRejectedSelfPurchase, "cannot purchase your own product")
+	}
+	return http.StatusOK, nil
+}
+
+type maxQuantityRule struct {
+	max int
+}
+
+func (r maxQuantityRule) Check(ctx context.Context, purchase Purchase) (int, error) {
+	if r.max > 0 && purchase.Quantity > r.max {
+		return rejectPurchase(PurchaseRejectedQuantityExceeded, fmt.Sprintf("cannot purchase more than %d units in one order", r.max))
+	}
+	return http.StatusOK, nil
+}
+
+// buyerLimitRule rejects early the purchases over the limit, the payment and checkout transactions check it
+// again under the product row lock so concurrent purchases cannot go over it
+type buyerLimitRule struct {
+	paymentRepo repository.PaymentRepository
+}
+
+func (r buyerLimitRule) Check(ctx context.Context, purchase Purchase) (int, error) {
+	limit := purchase.Product.PurchaseLimit
+	if limit <= 0 {
+		return http.StatusOK, nil
+	}
+
+	purchased, code, err := r.paymentRepo.SumQuantityByBuyer(ctx, purchase.BuyerID, purchase.Product.ID)
+	if err != nil {
+		return code, err
+	}
+
+	if purchased+purchase.Quantity > limit {
+		return rejectPurchase(PurchaseRejectedBuyerLimit, fmt.Sprintf("each buyer can purchase at most %d units of this product", limit))
+	}
+	return http.StatusOK, nil
+}
+
+type sellerSuspendedRule struct {
+	userRepo repository.UserRepository
+}
+
+func (r sellerSuspendedRule) Check(ctx context.Context, purchase Purchase) (int, error) {
+	seller, code, err := r.userRepo.FindByID(ctx, purchase.Product.UserID)
+	if err != nil {
+		return code, err
+	}
+
+	if seller.IsSuspended {
+		return rejectPurchase(PurchaseRejectedSellerSuspended, "seller account is suspended")
+	}
+	return http.StatusOK, nil
+}
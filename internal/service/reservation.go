@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"ecomm/internal/helper/errorer"
+	"ecomm/internal/helper/validator"
+	"ecomm/internal/model/entity"
+	"ecomm/internal/model/request"
+	"ecomm/internal/model/response"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func (s *service) ReserveProduct(ctx context.Context, req request.ReserveProduct) (*response.Reservation, int, error) {
+	if err := validator.ValidateStruct(&req); err != nil {
+		return nil, http.StatusBadRequest, errors.Wrap(errorer.ErrInputRequest(err), errorer.ErrInputRequest(err).Error())
+	}
+
+	prd, code, err := s.productRepo.FindByID(ctx, req.ProductId)
+	if err != nil {
+		return nil, code, err
+	}
+
+	if code, err := s.checkPurchase(ctx, Purchase{BuyerID: req.UserID, Product: prd, Quantity: req.Quantity}); err != nil {
+		return nil, code, err
+	}
+
+	now := time.Now()
+	ent, code, err := s.reservationRepo.Create(ctx, entity.Reservation{
+		UserID:    req.UserID,
+		ProductID: req.ProductId,
+		Quantity:  req.Quantity,
+		ExpiresAt: now.Add(s.cfg.ReservationTTL).UnixMilli(),
+		CreatedAt: now.UnixMilli(),
+		UpdatedAt: now.UnixMilli(),
+	}, s.cfg.MaxPurchaseQuantity)
+	if err != nil {
+		return nil, code, err
+	}
+
+	return &response.Reservation{
+		ID:        strconv.Itoa(int(ent.ID)),
+		ProductID: strconv.Itoa(int(ent.ProductID)),
+		Quantity:  ent.Quantity,
+		Status:    ent.Status,
+		UserID:    ent.UserID,
+		ExpiresAt: ent.ExpiresAt,
+		CreatedAt: ent.CreatedAt,
+		UpdatedAt: ent.UpdatedAt,
+	}, code, nil
+}
+
+// ReleaseExpiredReservations gives the stock held by expired reservations back to their products
+func (s *service) ReleaseExpiredReservations(ctx context.Context) (int64, int, error) {
+	return s.reservationRepo.ReleaseExpired(ctx, time.Now().UnixMilli())
+}
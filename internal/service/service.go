@@ -6,56 +6,129 @@ import (
 	"ecomm/internal/model/request"
 	"ecomm/internal/model/response"
 	"ecomm/internal/repository"
+	"io"
 	"mime/multipart"
+	"time"
 
 	"github.com/rs/zerolog"
 )
 
 type Service interface {
 	// Product
-	GetProducts(ctx context.Context, req request.GetProducts) ([]response.Product, *common.Meta, int, error)
+	GetProducts(ctx context.Context, req request.GetProducts) (*response.ProductList, *common.Meta, int, error)
+	GetSuggestions(ctx context.Context, req request.GetSuggestions) ([]response.Suggestion, int, error)
 	GetProductByID(ctx context.Context, id int64) (*response.Product, int, error)
 	GetProductWithSellerByID(ctx context.Context, id int64) (*response.Product, *response.SellerDetail, int, error)
-	DeleteProductByID(ctx context.Context, id int64) (int, error)
+	DeleteProductByID(ctx context.Context, id int64, version int, userId int64) (int, error)
+	RestoreProductByID(ctx context.Context, id int64, userId int64) (int, error)
+	PurgeDeletedProducts(ctx context.Context) (int64, int, error)
 	CreateProduct(ctx context.Context, req request.Product) (*response.Product, int, error)
+	ImportProducts(ctx context.Context, req request.ImportProducts, file *multipart.FileHeader) (*response.ProductImport, int, error)
+	ExportProducts(ctx context.Context, req request.Export, w io.Writer) (int, error)
+	UpdateProductStocks(ctx context.Context, req request.UpdateProductStocks) ([]response.ProductStockUpdate, int, error)
 	UpdateProductByID(ctx context.Context, req request.UpdateProduct) (*response.Product, int, error)
 	UpdateProductStockByID(ctx context.Context, req request.UpdateProductStock) (int, error)
-	PurchaseProduct(ctx context.Context, req request.PurchaseProduct) (int, error)
+	PurchaseProduct(ctx context.Context, req request.PurchaseProduct) (*response.Payment, int, error)
+	GetProductHistory(ctx context.Context, req request.GetProductHistory) ([]response.ProductVersion, *common.Meta, int, error)
+	// Price alert
+	SubscribePriceAlert(ctx context.Context, req request.SubscribePriceAlert) (*response.PriceAlert, int, error)
+	UnsubscribePriceAlert(ctx context.Context, productId int64, userId int64) (int, error)
+	// Notification
+	GetNotifications(ctx context.Context, req request.GetNotifications) ([]response.Notification, *common.Meta, int, error)
+	MarkNotificationRead(ctx context.Context, id int64, userId int64) (int, error)
+	// Reservation
+	ReserveProduct(ctx context.Context, req request.ReserveProduct) (*response.Reservation, int, error)
+	ReleaseExpiredReservations(ctx context.Context) (int64, int, error)
+	// Product image
+	AddProductImage(ctx context.Context, req request.AddProductImage) ([]response.ProductImage, int, error)
+	ReorderProductImages(ctx context.Context, req request.ReorderProductImages) ([]response.ProductImage, int, error)
+	DeleteProductImage(ctx context.Context, productId int64, imageId int64, userId int64) ([]response.ProductImage, int, error)
+	// Tag
+	GetTags(ctx context.Context, req request.GetTags) ([]response.Tag, int, error)
+	// Category
+	GetCategories(ctx context.Context) ([]response.Category, int, error)
+	CreateCategory(ctx context.Context, req request.Category) (*response.Category, int, error)
+	UpdateCategoryByID(ctx context.Context, req request.UpdateCategory) (int, error)
+	DeleteCategoryByID(ctx context.Context, id int64) (int, error)
+	// Cart
+	GetCart(ctx context.Context, userId int64) (*response.Cart, int, error)
+	AddCartItem(ctx context.Context, req request.AddCartItem) (int, error)
+	UpdateCartItem(ctx context.Context, req request.UpdateCartItem) (int, error)
+	DeleteCartItem(ctx context.Context, userId int64, productId int64) (int, error)
+	Checkout(ctx context.Context, req request.Checkout) ([]response.Order, int, error)
+	// Payment
+	GetPayments(ctx context.Context, req request.GetPayments) ([]response.Payment, *common.Meta, int, error)
+	GetPaymentByID(ctx context.Context, id int64) (*response.Payment, int, error)
+	UpdatePaymentStatus(ctx context.Context, req request.UpdatePaymentStatus) (*response.Payment, int, error)
+	ExportSales(ctx context.Context, req request.Export, w io.Writer) (int, error)
 	// User
 	Register(ctx context.Context, payload request.Register) (*response.Login, int, error)
 	Login(ctx context.Context, payload request.Login) (*response.Login, int, error)
 	GetUserByID(ctx context.Context, id int64) (*response.User, int, error)
+	// Idempotency
+	AcquireIdempotencyKey(ctx context.Context, req request.IdempotencyKey) (*response.IdempotencyKey, int, error)
+	SaveIdempotencyResponse(ctx context.Context, req request.IdempotencyKey) (int, error)
+	ReleaseIdempotencyKey(ctx context.Context, userId int64, key string) (int, error)
+	DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, int, error)
 	// s3
 	UploadImage(ctx context.Context, file *multipart.FileHeader) (string, int, error)
 	// bank
 	GetBanks(ctx context.Context, userId int64) ([]response.Bank, int, error)
 	GetBankByID(ctx context.Context, id int64) (*response.Bank, int, error)
-	DeleteBankByID(ctx context.Context, id int64) (int, error)
+	DeleteBankByID(ctx context.Context, id int64, version int) (int, error)
 	UpdateBankByID(ctx context.Context, ent request.UpdateBank) (int, error)
 	CreateBank(ctx context.Context, ent request.CreateBank) (int, error)
 }
 
 type Config struct {
-	Salt      int
-	JwtSecret string
+	Salt           int
+	JwtSecret      string
+	ReservationTTL time.Duration
+	IdempotencyTTL time.Duration
+	// ProductRetention is how long a deleted product can be restored before it is purged
+	ProductRetention time.Duration
+	// MaxPurchaseQuantity is the most units of a product allowed in one order, 0 means unlimited
+	MaxPurchaseQuantity int
 }
 
 type service struct {
-	cfg         Config
-	log         zerolog.Logger
-	productRepo repository.ProductRepository
-	userRepo    repository.UserRepository
-	s3Repo      repository.S3Repository
-	bankRepo    repository.BankRepository
+	cfg              Config
+	log              zerolog.Logger
+	productRepo      repository.ProductRepository
+	userRepo         repository.UserRepository
+	s3Repo           repository.S3Repository
+	bankRepo         repository.BankRepository
+	paymentRepo      repository.PaymentRepository
+	reservationRepo  repository.ReservationRepository
+	cartRepo         repository.CartRepository
+	orderRepo        repository.OrderRepository
+	idempotencyRepo  repository.IdempotencyRepository
+	categoryRepo     repository.CategoryRepository
+	tagRepo          repository.TagRepository
+	productImageRepo repository.ProductImageRepository
+	priceAlertRepo   repository.PriceAlertRepository
+	notificationRepo repository.NotificationRepository
+	purchaseRules    []PurchaseRule
 }
 
-func New(cfg Config, logger zerolog.Logger, productRepo repository.ProductRepository, userRepo repository.UserRepository, s3Repo repository.S3Repository, bankRepo repository.BankRepository) Service {
+func New(cfg Config, logger zerolog.Logger, productRepo repository.ProductRepository, userRepo repository.UserRepository, s3Repo repository.S3Repository, bankRepo repository.BankRepository, paymentRepo repository.PaymentRepository, reservationRepo repository.ReservationRepository, cartRepo repository.CartRepository, orderRepo repository.OrderRepository, idempotencyRepo repository.IdempotencyRepository, categoryRepo repository.CategoryRepository, tagRepo repository.TagRepository, productImageRepo repository.ProductImageRepository, priceAlertRepo repository.PriceAlertRepository, notificationRepo repository.NotificationRepository) Service {
 	return &service{
-		cfg:         cfg,
-		log:         logger,
-		productRepo: productRepo,
-		userRepo:    userRepo,
-		s3Repo:      s3Repo,
-		bankRepo:    bankRepo,
+		cfg:              cfg,
+		log:              logger,
+		productRepo:      productRepo,
+		userRepo:         userRepo,
+		s3Repo:           s3Repo,
+		bankRepo:         bankRepo,
+		paymentRepo:      paymentRepo,
+		reservationRepo:  reservationRepo,
+		cartRepo:         cartRepo,
+		orderRepo:        orderRepo,
+		idempotencyRepo:  idempotencyRepo,
+		categoryRepo:     categoryRepo,
+		tagRepo:          tagRepo,
+		productImageRepo: productImageRepo,
+		priceAlertRepo:   priceAlertRepo,
+		notificationRepo: notificationRepo,
+		purchaseRules:    defaultPurchaseRules(cfg, userRepo, paymentRepo),
 	}
 }
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"ecomm/internal/helper/errorer"
+	"ecomm/internal/helper/validator"
+	"ecomm/internal/model/entity"
+	"ecomm/internal/model/request"
+	"ecomm/internal/model/response"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+func (s *service) GetTags(ctx context.Context, req request.GetTags) ([]response.Tag, int, error) {
+	if err := validator.ValidateStruct(&req); err != nil {
+		return nil, http.StatusBadRequest, errors.Wrap(errorer.ErrInputRequest(err), errorer.ErrInputRequest(err).Error())
+	}
+
+	ent, code, err := s.tagRepo.FindAll(ctx, entity.GetAllTagFilter{
+		Prefix: strings.ToLower(strings.TrimSpace(req.Query)),
+		Limit:  req.Limit,
+	})
+	if err != nil {
+		return nil, code, err
+	}
+
+	list := make([]response.Tag, len(ent))
+	for i, v := range ent {
+		list[i] = response.Tag{
+			Name:  v.Name,
+			Count: v.Count,
+		}
+	}
+
+	return list, http.StatusOK, nil
+}
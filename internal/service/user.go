@@ -120,6 +120,7 @@ func (s *service) GetUserByID(ctx context.Context, id int64) (*response.User, in
 		ID:        user.ID,
 		Username:  user.Username,
 		Name:      user.Name,
+		IsAdmin:   user.IsAdmin,
 		CreatedAt: user.CreatedAt,
 		UpdatedAt: user.UpdatedAt,
 	}, code, nil